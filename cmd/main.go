@@ -6,8 +6,10 @@ import (
 	"go-subscriptions-service/internal/handler"
 	"go-subscriptions-service/internal/repo"
 	"go-subscriptions-service/internal/service"
+	"go-subscriptions-service/pgk/currency"
 	"log"
 	"net/http"
+	"os"
 
 	_ "go-subscriptions-service/docs"
 
@@ -30,12 +32,32 @@ func main() {
 		log.Fatal("Cannot connect to db: ", err)
 	}
 
+	rates := currency.NewRates()
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		loaded, err := currency.LoadCSV(path)
+		if err != nil {
+			log.Fatal("Cannot load exchange rates: ", err)
+		}
+		rates = loaded
+	} else {
+		log.Println("EXCHANGE_RATES_FILE is not set, only conversions within one currency are available")
+	}
+
 	subscriptionRepo := repo.NewSubscriptionRepo(conn)
-	subscriptionService := service.NewSubscriptionService(subscriptionRepo)
+	budgetRepo := repo.NewBudgetRepo(conn)
+	reportRepo := repo.NewReportRepo(conn)
+	subscriptionService := service.NewSubscriptionService(subscriptionRepo, budgetRepo, rates)
+	budgetService := service.NewBudgetService(budgetRepo, subscriptionService)
+	reportService := service.NewReportService(reportRepo, rates)
 	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
+	budgetHandler := handler.NewBudgetHandler(budgetService)
+	adminHandler := handler.NewAdminHandler(reportService, os.Getenv("ADMIN_TOKEN"))
 
 	router := mux.NewRouter()
+	router.Use(handler.RequestID)
 	subscriptionHandler.RegisterRouters(router)
+	budgetHandler.RegisterRouters(router)
+	adminHandler.RegisterRouters(router)
 
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
@@ -1,9 +1,58 @@
 package dto
 
+import "encoding/json"
+
+// Цены и суммы принимаются числом или десятичной строкой ("199.90") и
+// разбираются в money.Money в валюте запроса. Пустой или null end_date —
+// подписка без даты окончания.
 type SubscriptionRequest struct {
-	ServiceName string `json:"service_name"`
-	Price       int    `json:"price"`
-	UserID      string `json:"user_id"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date"`
+	ServiceName     string      `json:"service_name"`
+	Price           json.Number `json:"price" swaggertype:"string" example:"199.90"`
+	Currency        string      `json:"currency"`
+	BillingCycle    string      `json:"billing_cycle"`
+	BillingInterval int         `json:"billing_interval"`
+	UserID          string      `json:"user_id"`
+	StartDate       string      `json:"start_date"`
+	EndDate         string      `json:"end_date"`
+	TrialEndDate    string      `json:"trial_end_date"`
+	TrialMonths     int         `json:"trial_months"`
+}
+
+// SubscriptionPatchRequest — тело PATCH в формате JSON Merge Patch (RFC
+// 7396). Поля хранят исходный JSON, чтобы отличать отсутствующее поле (не
+// меняется) от null (очищает end_date или trial_end_date).
+type SubscriptionPatchRequest struct {
+	ServiceName     json.RawMessage `json:"service_name" swaggertype:"string"`
+	Price           json.RawMessage `json:"price" swaggertype:"string" example:"199.90"`
+	Currency        json.RawMessage `json:"currency" swaggertype:"string"`
+	BillingCycle    json.RawMessage `json:"billing_cycle" swaggertype:"string"`
+	BillingInterval json.RawMessage `json:"billing_interval" swaggertype:"integer"`
+	UserID          json.RawMessage `json:"user_id" swaggertype:"string"`
+	StartDate       json.RawMessage `json:"start_date" swaggertype:"string"`
+	EndDate         json.RawMessage `json:"end_date" swaggertype:"string"`
+	TrialEndDate    json.RawMessage `json:"trial_end_date" swaggertype:"string"`
+	TrialMonths     json.RawMessage `json:"trial_months" swaggertype:"integer"`
+}
+
+type PriceChangeRequest struct {
+	Price         json.Number `json:"price" swaggertype:"string" example:"199.90"`
+	EffectiveFrom string      `json:"effective_from"`
+}
+
+// CancelRequest — необязательное тело запроса на отмену подписки.
+type CancelRequest struct {
+	EffectiveMonth string `json:"effective_month"`
+	Reason         string `json:"reason"`
+}
+
+// PauseRequest — необязательное тело запроса на паузу подписки.
+type PauseRequest struct {
+	EffectiveMonth string `json:"effective_month"`
+}
+
+type BudgetRequest struct {
+	UserID   string      `json:"user_id"`
+	Amount   json.Number `json:"amount" swaggertype:"string" example:"199.90"`
+	Currency string      `json:"currency"`
+	Enforce  bool        `json:"enforce"`
 }
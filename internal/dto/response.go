@@ -0,0 +1,345 @@
+package dto
+
+import (
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/pgk/money"
+	"go-subscriptions-service/pgk/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ответы API. Поля названы в snake_case, даты выводятся в формате
+// utils.DateLayout (месяцы — первым числом), моменты времени — в RFC 3339,
+// суммы — десятичными строками в валюте ответа.
+
+// SubscriptionResponse — подписка вместе с вычисляемыми сервисом полями.
+type SubscriptionResponse struct {
+	ID                    uuid.UUID   `json:"id"`
+	ServiceName           string      `json:"service_name"`
+	Price                 money.Money `json:"price"`
+	BillingCycle          string      `json:"billing_cycle"`
+	BillingInterval       int         `json:"billing_interval,omitempty"`
+	BillingAnchorDay      int         `json:"billing_anchor_day"`
+	UserID                uuid.UUID   `json:"user_id"`
+	StartDate             utils.Date  `json:"start_date"`
+	EndDate               *utils.Date `json:"end_date"`
+	TrialEndDate          *utils.Date `json:"trial_end_date"`
+	AllowOverlap          bool        `json:"allow_overlap"`
+	CancelledAt           *time.Time  `json:"cancelled_at"`
+	CancelReason          *string     `json:"cancel_reason"`
+	PausedFrom            *utils.Date `json:"paused_from"`
+	Status                string      `json:"status"`
+	NormalizedMonthlyCost money.Money `json:"normalized_monthly_cost"`
+	InTrial               bool        `json:"in_trial"`
+	Paused                bool        `json:"paused"`
+}
+
+func NewSubscriptionResponse(sub *model.Subscription) SubscriptionResponse {
+	return SubscriptionResponse{
+		ID:                    sub.ID,
+		ServiceName:           sub.ServiceName,
+		Price:                 sub.Price,
+		BillingCycle:          sub.BillingCycle,
+		BillingInterval:       sub.BillingInterval,
+		BillingAnchorDay:      sub.BillingAnchorDay,
+		UserID:                sub.UserID,
+		StartDate:             utils.Date(sub.StartDate),
+		EndDate:               utils.NewDate(sub.EndDate),
+		TrialEndDate:          utils.NewDate(sub.TrialEndDate),
+		AllowOverlap:          sub.AllowOverlap,
+		CancelledAt:           sub.CancelledAt,
+		CancelReason:          sub.CancelReason,
+		PausedFrom:            utils.NewDate(sub.PausedFrom),
+		Status:                sub.Status,
+		NormalizedMonthlyCost: sub.NormalizedMonthlyCost,
+		InTrial:               sub.InTrial,
+		Paused:                sub.Paused,
+	}
+}
+
+func NewSubscriptionResponses(subs []model.Subscription) []SubscriptionResponse {
+	res := make([]SubscriptionResponse, 0, len(subs))
+	for i := range subs {
+		res = append(res, NewSubscriptionResponse(&subs[i]))
+	}
+	return res
+}
+
+// SubscriptionListResponse — страница списка подписок; NextCursor равен
+// null на последней странице.
+type SubscriptionListResponse struct {
+	Items      []SubscriptionResponse `json:"items"`
+	NextCursor *string                `json:"next_cursor"`
+}
+
+// BatchItemResponse — результат создания одной подписки пакета: id
+// созданной подписки или ошибка с теми же code и field, что в
+// problem+json, включая conflicting_id. Index — позиция подписки в теле
+// запроса.
+type BatchItemResponse struct {
+	Index         int        `json:"index"`
+	Status        int        `json:"status"`
+	ID            *uuid.UUID `json:"id,omitempty"`
+	Code          string     `json:"code,omitempty"`
+	Detail        string     `json:"detail,omitempty"`
+	Field         string     `json:"field,omitempty"`
+	ConflictingID *uuid.UUID `json:"conflicting_id,omitempty"`
+}
+
+// BatchResponse — результаты пакетного создания подписок в порядке тела
+// запроса.
+type BatchResponse struct {
+	Atomic  bool                `json:"atomic"`
+	Created int                 `json:"created"`
+	Failed  int                 `json:"failed"`
+	Items   []BatchItemResponse `json:"items"`
+}
+
+type PriceChangeResponse struct {
+	ID             uuid.UUID   `json:"id"`
+	SubscriptionID uuid.UUID   `json:"subscription_id"`
+	Price          money.Money `json:"price"`
+	EffectiveFrom  utils.Date  `json:"effective_from"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+func NewPriceChangeResponse(c *model.PriceChange) PriceChangeResponse {
+	return PriceChangeResponse{
+		ID:             c.ID,
+		SubscriptionID: c.SubscriptionID,
+		Price:          c.Price,
+		EffectiveFrom:  utils.Date(c.EffectiveFrom),
+		CreatedAt:      c.CreatedAt,
+	}
+}
+
+func NewPriceChangeResponses(changes []model.PriceChange) []PriceChangeResponse {
+	res := make([]PriceChangeResponse, 0, len(changes))
+	for i := range changes {
+		res = append(res, NewPriceChangeResponse(&changes[i]))
+	}
+	return res
+}
+
+// PauseResponse — пауза подписки; у открытой паузы resumed_on равен null.
+type PauseResponse struct {
+	ID             uuid.UUID   `json:"id"`
+	SubscriptionID uuid.UUID   `json:"subscription_id"`
+	PausedFrom     utils.Date  `json:"paused_from"`
+	ResumedOn      *utils.Date `json:"resumed_on"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+func NewPauseResponses(pauses []model.Pause) []PauseResponse {
+	res := make([]PauseResponse, 0, len(pauses))
+	for _, p := range pauses {
+		res = append(res, PauseResponse{
+			ID:             p.ID,
+			SubscriptionID: p.SubscriptionID,
+			PausedFrom:     utils.Date(p.PausedFrom),
+			ResumedOn:      utils.NewDate(p.ResumedOn),
+			CreatedAt:      p.CreatedAt,
+		})
+	}
+	return res
+}
+
+type ServiceTotalResponse struct {
+	ServiceName string  `json:"service_name"`
+	Amount      string  `json:"amount"`
+	Share       float64 `json:"share"`
+}
+
+// TotalAmountResponse — сумма за период; Services заполняется при
+// group_by=service_name.
+type TotalAmountResponse struct {
+	TotalAmount string                 `json:"total_amount"`
+	Currency    string                 `json:"currency"`
+	Services    []ServiceTotalResponse `json:"services,omitempty"`
+}
+
+func NewTotalAmountResponse(total int64, services []model.ServiceTotal, cur string) TotalAmountResponse {
+	res := TotalAmountResponse{TotalAmount: formatAmount(total, cur), Currency: cur}
+	if services != nil {
+		res.Services = make([]ServiceTotalResponse, 0, len(services))
+	}
+	for _, st := range services {
+		res.Services = append(res.Services, ServiceTotalResponse{
+			ServiceName: st.ServiceName,
+			Amount:      formatAmount(st.Amount, cur),
+			Share:       st.Share,
+		})
+	}
+	return res
+}
+
+type ServiceAmountResponse struct {
+	ServiceName string `json:"service_name"`
+	Amount      string `json:"amount"`
+	Trial       bool   `json:"trial,omitempty"`
+}
+
+type MonthlyAmountResponse struct {
+	Month    string                  `json:"month"`
+	Amount   string                  `json:"amount"`
+	Currency string                  `json:"currency"`
+	Trial    bool                    `json:"trial,omitempty"`
+	Services []ServiceAmountResponse `json:"services,omitempty"`
+}
+
+func NewMonthlyAmountResponses(amounts []model.MonthlyAmount, cur string) []MonthlyAmountResponse {
+	res := make([]MonthlyAmountResponse, 0, len(amounts))
+	for _, a := range amounts {
+		item := MonthlyAmountResponse{
+			Month:    utils.FormatDate(a.Month),
+			Amount:   formatAmount(a.Amount, cur),
+			Currency: cur,
+			Trial:    a.Trial,
+		}
+		for _, sa := range a.Services {
+			item.Services = append(item.Services, ServiceAmountResponse{ServiceName: sa.ServiceName, Amount: formatAmount(sa.Amount, cur), Trial: sa.Trial})
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+type ForecastResponse struct {
+	Months      []MonthlyAmountResponse `json:"months"`
+	TotalAmount string                  `json:"total_amount"`
+	Currency    string                  `json:"currency"`
+}
+
+func NewForecastResponse(forecast *model.Forecast, cur string) ForecastResponse {
+	return ForecastResponse{
+		Months:      NewMonthlyAmountResponses(forecast.Months, cur),
+		TotalAmount: formatAmount(forecast.Total, cur),
+		Currency:    cur,
+	}
+}
+
+type UpcomingChargeResponse struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	ServiceName    string    `json:"service_name"`
+	NextChargeDate string    `json:"next_charge_date"`
+	Amount         string    `json:"amount"`
+	Currency       string    `json:"currency"`
+	Trial          bool      `json:"trial,omitempty"`
+}
+
+func NewUpcomingChargeResponses(charges []model.UpcomingCharge) []UpcomingChargeResponse {
+	res := make([]UpcomingChargeResponse, 0, len(charges))
+	for _, c := range charges {
+		res = append(res, UpcomingChargeResponse{
+			SubscriptionID: c.SubscriptionID,
+			ServiceName:    c.ServiceName,
+			NextChargeDate: utils.FormatDate(c.ChargeDate),
+			Amount:         formatAmount(c.Amount, c.Currency),
+			Currency:       c.Currency,
+			Trial:          c.Trial,
+		})
+	}
+	return res
+}
+
+type BudgetResponse struct {
+	UserID    uuid.UUID   `json:"user_id"`
+	Amount    money.Money `json:"amount"`
+	Enforce   bool        `json:"enforce"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+func NewBudgetResponse(b *model.Budget) BudgetResponse {
+	return BudgetResponse{
+		UserID:    b.UserID,
+		Amount:    b.Amount,
+		Enforce:   b.Enforce,
+		CreatedAt: b.CreatedAt,
+		UpdatedAt: b.UpdatedAt,
+	}
+}
+
+type BudgetMonthResponse struct {
+	Month      string `json:"month"`
+	Spent      string `json:"spent"`
+	Budget     string `json:"budget"`
+	Remaining  string `json:"remaining"`
+	Currency   string `json:"currency"`
+	OverBudget bool   `json:"over_budget"`
+}
+
+func NewBudgetMonthResponses(months []model.BudgetMonth) []BudgetMonthResponse {
+	res := make([]BudgetMonthResponse, 0, len(months))
+	for _, m := range months {
+		res = append(res, BudgetMonthResponse{
+			Month:      utils.FormatDate(m.Month),
+			Spent:      formatAmount(m.Spent, m.Currency),
+			Budget:     formatAmount(m.Budget, m.Currency),
+			Remaining:  formatAmount(m.Budget-m.Spent, m.Currency),
+			Currency:   m.Currency,
+			OverBudget: m.OverBudget,
+		})
+	}
+	return res
+}
+
+type RevenueMonthResponse struct {
+	Month               string `json:"month"`
+	Subscribers         int    `json:"subscribers"`
+	ActiveSubscriptions int    `json:"active_subscriptions"`
+	Revenue             string `json:"revenue"`
+}
+
+type ServiceRevenueResponse struct {
+	ServiceName  string                 `json:"service_name"`
+	Months       []RevenueMonthResponse `json:"months"`
+	TotalRevenue string                 `json:"total_revenue"`
+	Currency     string                 `json:"currency"`
+}
+
+func NewServiceRevenueResponses(report []model.ServiceRevenue, cur string) []ServiceRevenueResponse {
+	res := make([]ServiceRevenueResponse, 0, len(report))
+	for _, sr := range report {
+		item := ServiceRevenueResponse{
+			ServiceName:  sr.ServiceName,
+			TotalRevenue: formatAmount(sr.TotalRevenue, cur),
+			Currency:     cur,
+			Months:       make([]RevenueMonthResponse, 0, len(sr.Months)),
+		}
+		for _, m := range sr.Months {
+			item.Months = append(item.Months, RevenueMonthResponse{
+				Month:               utils.FormatDate(m.Month),
+				Subscribers:         m.Subscribers,
+				ActiveSubscriptions: m.ActiveSubscriptions,
+				Revenue:             formatAmount(m.Revenue, cur),
+			})
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+// formatAmount форматирует сумму в минимальных единицах валюты cur
+// десятичной строкой ("199.90").
+func formatAmount(amount int64, cur string) string {
+	return money.New(amount, cur).String()
+}
+
+// ProblemResponse — ошибка в формате RFC 7807 (application/problem+json).
+// Code — стабильный машинный код ошибки, Field — поле тела или параметр
+// запроса, вызвавший ошибку, ConflictingID — существующая подписка, с
+// которой пересекается запрошенная (для subscription_overlap), RequestID —
+// id запроса из X-Request-ID.
+type ProblemResponse struct {
+	Type          string     `json:"type"`
+	Title         string     `json:"title"`
+	Status        int        `json:"status"`
+	Detail        string     `json:"detail"`
+	Instance      string     `json:"instance"`
+	Code          string     `json:"code"`
+	Field         string     `json:"field,omitempty"`
+	ConflictingID *uuid.UUID `json:"conflicting_id,omitempty"`
+	RequestID     string     `json:"request_id,omitempty"`
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-subscriptions-service/internal/dto"
+	"go-subscriptions-service/internal/service"
+	"go-subscriptions-service/pgk/currency"
+	"go-subscriptions-service/pgk/utils"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminHandler обслуживает отчёты по всем пользователям. Доступ к ним
+// открыт только с заголовком X-Admin-Token, совпадающим с токеном из
+// конфигурации; без настроенного токена ручки отключены.
+type AdminHandler struct {
+	service service.ReportService
+	token   string
+}
+
+func NewAdminHandler(s service.ReportService, token string) *AdminHandler {
+	return &AdminHandler{service: s, token: token}
+}
+
+func (h *AdminHandler) RegisterRouters(r *mux.Router) {
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.Use(h.requireAdmin)
+	admin.HandleFunc("/reports/revenue", h.GetRevenueReport).Methods("GET")
+}
+
+func (h *AdminHandler) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.token == "" {
+			log.Println("requireAdmin (handler) error: admin token is not configured")
+			writeProblem(w, r, http.StatusForbidden, codeForbidden, errors.New("admin API is disabled"))
+			return
+		}
+
+		token := r.Header.Get("X-Admin-Token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			log.Println("requireAdmin (handler) error: invalid admin token")
+			writeProblem(w, r, http.StatusUnauthorized, codeUnauthorized, errors.New("invalid admin token"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetRevenueReport godoc
+// @Summary Отчёт по выручке сервисов
+// @Description Для каждого сервиса по всем пользователям возвращает помесячно число подписчиков, активных подписок и выручку за период. Требует заголовок X-Admin-Token
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Токен администратора"
+// @Param from query string true "Начало периода (YYYY-MM-DD или MM-YYYY, учитывается месяц)"
+// @Param to query string true "Конец периода (YYYY-MM-DD или MM-YYYY, учитывается месяц)"
+// @Param currency query string false "Валюта выручки (RUB, USD, EUR; по умолчанию RUB)"
+// @Success 200 {array} dto.ServiceRevenueResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверные параметры запроса"
+// @Failure 401 {object} dto.ProblemResponse "Неверный токен администратора"
+// @Failure 403 {object} dto.ProblemResponse "Админские ручки отключены"
+// @Failure 422 {object} dto.ProblemResponse "Нет курса валюты для пересчёта суммы за месяц"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /admin/reports/revenue [get]
+func (h *AdminHandler) GetRevenueReport(w http.ResponseWriter, r *http.Request) {
+	from, err := utils.ParseMonth(r.URL.Query().Get("from"))
+	if err != nil {
+		log.Println("GetRevenueReport (handler) error: from utils.ParseMonth failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("from", "invalid from date (expected YYYY-MM-DD or MM-YYYY)"))
+		return
+	}
+
+	to, err := utils.ParseMonth(r.URL.Query().Get("to"))
+	if err != nil {
+		log.Println("GetRevenueReport (handler) error: to utils.ParseMonth failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("to", "invalid to date (expected YYYY-MM-DD or MM-YYYY)"))
+		return
+	}
+
+	cur := r.URL.Query().Get("currency")
+	if cur == "" {
+		cur = currency.Default
+	} else if !currency.IsSupported(cur) {
+		log.Println("GetRevenueReport (handler) error: unsupported currency: ", cur)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("currency", fmt.Sprintf("unsupported currency (expected one of %s)", currency.SupportedList())))
+		return
+	}
+
+	report, err := h.service.GetRevenueReport(from, to, cur)
+	if err != nil {
+		log.Println("GetRevenueReport (handler) error: failed to get revenue report: ", err)
+		writeError(w, r, err, "failed to get revenue report")
+		return
+	}
+
+	res := dto.NewServiceRevenueResponses(report, cur)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+	log.Printf("GetRevenueReport (handler) success: services=%d", len(res))
+}
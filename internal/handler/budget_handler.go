@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"encoding/json"
+	"go-subscriptions-service/internal/dto"
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/internal/service"
+	"go-subscriptions-service/pgk/currency"
+	"go-subscriptions-service/pgk/money"
+	"go-subscriptions-service/pgk/utils"
+	"go-subscriptions-service/pgk/validator"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type BudgetHandler struct {
+	service service.BudgetService
+}
+
+func NewBudgetHandler(s service.BudgetService) *BudgetHandler {
+	return &BudgetHandler{service: s}
+}
+
+func (h *BudgetHandler) RegisterRouters(r *mux.Router) {
+	r.HandleFunc("/budget", h.CreateBudget).Methods("POST")
+	r.HandleFunc("/budget/{user_id}", h.GetBudget).Methods("GET")
+	r.HandleFunc("/budget/{user_id}", h.UpdateBudget).Methods("PUT")
+	r.HandleFunc("/budget/{user_id}", h.DeleteBudget).Methods("DELETE")
+	r.HandleFunc("/budget/{user_id}/status", h.GetBudgetStatus).Methods("GET")
+}
+
+// CreateBudget godoc
+// @Summary Создать бюджет
+// @Description Задаёт месячный бюджет пользователя на подписки. При enforce=true создание и изменение подписок, выводящие будущий месяц за бюджет, отклоняются с 409
+// @Tags budget
+// @Accept json
+// @Produce json
+// @Param request body dto.BudgetRequest true "Данные бюджета"
+// @Success 201 {object} dto.BudgetResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверные данные"
+// @Failure 409 {object} dto.ProblemResponse "Бюджет уже существует"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /budget [post]
+func (h *BudgetHandler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	var req dto.BudgetRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("CreateBudget (handler) error: json.NewDecoder failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, errInvalidJSON)
+		return
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		log.Println("CreateBudget (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("user_id", "invalid user_id"))
+		return
+	}
+
+	if err := validator.ValidateBudgetRequest(&req); err != nil {
+		log.Println("CreateBudget (handler) error: validateBudgetRequest failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
+	}
+
+	budget := newBudget(userID, &req)
+
+	if err := h.service.Create(&budget); err != nil {
+		log.Println("CreateBudget (handler) error: failed to create budget: ", err)
+		writeError(w, r, err, "failed to create budget")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.NewBudgetResponse(&budget))
+	log.Println("CreateBudget (handler) success: budget created")
+}
+
+// GetBudget godoc
+// @Summary Получить бюджет
+// @Description Возвращает месячный бюджет пользователя
+// @Tags budget
+// @Accept json
+// @Produce json
+// @Param user_id path string true "ID пользователя"
+// @Success 200 {object} dto.BudgetResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID"
+// @Failure 404 {object} dto.ProblemResponse "Бюджет не найден"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /budget/{user_id} [get]
+func (h *BudgetHandler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["user_id"])
+	if err != nil {
+		log.Println("GetBudget (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("user_id", "invalid user_id"))
+		return
+	}
+
+	budget, err := h.service.GetByUserID(userID)
+	if err != nil {
+		log.Println("GetBudget (handler) error: failed to get budget: ", err)
+		writeError(w, r, err, "failed to get budget")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.NewBudgetResponse(budget))
+	log.Println("GetBudget (handler) success: budget found")
+}
+
+// UpdateBudget godoc
+// @Summary Обновить бюджет
+// @Description Обновляет месячный бюджет пользователя
+// @Tags budget
+// @Accept json
+// @Produce json
+// @Param user_id path string true "ID пользователя"
+// @Param request body dto.BudgetRequest true "Новые данные бюджета (user_id в теле игнорируется)"
+// @Success 200 {object} dto.BudgetResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID или тело запроса"
+// @Failure 404 {object} dto.ProblemResponse "Бюджет не найден"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /budget/{user_id} [put]
+func (h *BudgetHandler) UpdateBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["user_id"])
+	if err != nil {
+		log.Println("UpdateBudget (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("user_id", "invalid user_id"))
+		return
+	}
+
+	var req dto.BudgetRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("UpdateBudget (handler) error: json.NewDecoder failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, errInvalidJSON)
+		return
+	}
+
+	if err := validator.ValidateBudgetRequest(&req); err != nil {
+		log.Println("UpdateBudget (handler) error: validateBudgetRequest failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
+	}
+
+	budget := newBudget(userID, &req)
+
+	if err := h.service.Update(&budget); err != nil {
+		log.Println("UpdateBudget (handler) error: failed to update budget: ", err)
+		writeError(w, r, err, "failed to update budget")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.NewBudgetResponse(&budget))
+	log.Println("UpdateBudget (handler) success: budget updated")
+}
+
+// DeleteBudget godoc
+// @Summary Удалить бюджет
+// @Description Удаляет месячный бюджет пользователя
+// @Tags budget
+// @Accept json
+// @Produce json
+// @Param user_id path string true "ID пользователя"
+// @Success 204 {string} string ""
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID"
+// @Failure 404 {object} dto.ProblemResponse "Бюджет не найден"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /budget/{user_id} [delete]
+func (h *BudgetHandler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["user_id"])
+	if err != nil {
+		log.Println("DeleteBudget (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("user_id", "invalid user_id"))
+		return
+	}
+
+	if err := h.service.Delete(userID); err != nil {
+		log.Println("DeleteBudget (handler) error: failed to delete budget: ", err)
+		writeError(w, r, err, "failed to delete budget")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Println("DeleteBudget (handler) success: budget deleted")
+}
+
+// GetBudgetStatus godoc
+// @Summary Получить исполнение бюджета
+// @Description Сравнивает расходы пользователя за каждый месяц периода с его бюджетом (в валюте бюджета)
+// @Tags budget
+// @Accept json
+// @Produce json
+// @Param user_id path string true "ID пользователя"
+// @Param from query string true "Начало периода (YYYY-MM-DD или MM-YYYY, учитывается месяц)"
+// @Param to query string true "Конец периода (YYYY-MM-DD или MM-YYYY, учитывается месяц)"
+// @Success 200 {array} dto.BudgetMonthResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверные параметры запроса"
+// @Failure 404 {object} dto.ProblemResponse "Бюджет не найден"
+// @Failure 422 {object} dto.ProblemResponse "Нет курса валюты для пересчёта суммы за месяц"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /budget/{user_id}/status [get]
+func (h *BudgetHandler) GetBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["user_id"])
+	if err != nil {
+		log.Println("GetBudgetStatus (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("user_id", "invalid user_id"))
+		return
+	}
+
+	from, err := utils.ParseMonth(r.URL.Query().Get("from"))
+	if err != nil {
+		log.Println("GetBudgetStatus (handler) error: from utils.ParseMonth failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("from", "invalid from date (expected YYYY-MM-DD or MM-YYYY)"))
+		return
+	}
+
+	to, err := utils.ParseMonth(r.URL.Query().Get("to"))
+	if err != nil {
+		log.Println("GetBudgetStatus (handler) error: to utils.ParseMonth failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("to", "invalid to date (expected YYYY-MM-DD or MM-YYYY)"))
+		return
+	}
+
+	months, err := h.service.GetStatus(userID, from, to)
+	if err != nil {
+		log.Println("GetBudgetStatus (handler) error: failed to get budget status: ", err)
+		writeError(w, r, err, "failed to get budget status")
+		return
+	}
+
+	res := dto.NewBudgetMonthResponses(months)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+	log.Printf("GetBudgetStatus (handler) success: user_id=%v, months=%d", userID, len(res))
+}
+
+func newBudget(userID uuid.UUID, req *dto.BudgetRequest) model.Budget {
+	if req.Currency == "" {
+		req.Currency = currency.Default
+	}
+
+	amount, _ := money.Parse(req.Amount.String(), req.Currency)
+
+	return model.Budget{
+		UserID:  userID,
+		Amount:  amount,
+		Enforce: req.Enforce,
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-subscriptions-service/internal/dto"
+	"go-subscriptions-service/internal/service"
+	"go-subscriptions-service/pgk/validator"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Коды ошибок в поле code ответа problem+json. Коды — часть контракта API:
+// клиенты ветвятся по ним, поэтому существующие коды не переименовываются.
+const (
+	codeInvalidJSON        = "invalid_json"
+	codeValidationFailed   = "validation_failed"
+	codeUnauthorized       = "unauthorized"
+	codeForbidden          = "forbidden"
+	codeNotFound           = "not_found"
+	codeConflict           = "conflict"
+	codeBudgetExceeded     = "budget_exceeded"
+	codeBudgetExists       = "budget_exists"
+	codeOverlap            = "subscription_overlap"
+	codeInvalidTransition  = "invalid_transition"
+	codePreconditionFailed = "precondition_failed"
+	codeRateUnavailable    = "rate_unavailable"
+	codeBatchAborted       = "batch_aborted"
+	codeInternal           = "internal_error"
+)
+
+var errInvalidJSON = errors.New("invalid JSON body")
+
+type requestIDKey struct{}
+
+// RequestID берёт id запроса из заголовка X-Request-ID или создаёт новый,
+// возвращает его в том же заголовке ответа и кладёт в контекст запроса.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// writeProblem отвечает ошибкой err в формате RFC 7807. Если err (или
+// обёрнутая в неё ошибка) — validator.FieldError, в ответ попадает поле.
+// Для 5xx в err передаётся безопасное для клиента описание, а не исходная
+// ошибка.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code string, err error) {
+	problem := dto.ProblemResponse{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.Error(),
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: requestID(r),
+	}
+
+	var fieldErr *validator.FieldError
+	if errors.As(err, &fieldErr) {
+		problem.Field = fieldErr.Field
+	}
+	problem.ConflictingID = conflictingID(err)
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// writeError отвечает ошибкой сервиса err со статусом и кодом из
+// errorStatus. Для 500 клиент получает описание internal вместо исходной
+// ошибки.
+func writeError(w http.ResponseWriter, r *http.Request, err error, internal string) {
+	status, code := errorStatus(err)
+	if status == http.StatusInternalServerError {
+		err = errors.New(internal)
+	}
+	writeProblem(w, r, status, code, err)
+}
+
+// errorStatus выбирает HTTP-статус и код ошибки сервиса err по её
+// категории: ErrValidation — 400, ErrNotFound — 404, ErrConflict — 409,
+// ErrPreconditionFailed — 412, ErrRateUnavailable — 422. Остальные ошибки
+// считаются внутренними — 500.
+func errorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		return http.StatusBadRequest, codeValidationFailed
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound, codeNotFound
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed, codePreconditionFailed
+	case errors.Is(err, service.ErrRateUnavailable):
+		return http.StatusUnprocessableEntity, codeRateUnavailable
+	case errors.Is(err, service.ErrConflict):
+		return http.StatusConflict, conflictCode(err)
+	}
+	return http.StatusInternalServerError, codeInternal
+}
+
+// conflictCode возвращает код конкретного конфликта или общий
+// codeConflict.
+func conflictCode(err error) string {
+	switch {
+	case errors.Is(err, service.ErrBudgetExceeded):
+		return codeBudgetExceeded
+	case errors.Is(err, service.ErrBudgetExists):
+		return codeBudgetExists
+	case errors.Is(err, service.ErrOverlap):
+		return codeOverlap
+	case errors.Is(err, service.ErrInvalidTransition):
+		return codeInvalidTransition
+	}
+	return codeConflict
+}
+
+// conflictingID возвращает id пересекающейся подписки, если err —
+// service.OverlapError.
+func conflictingID(err error) *uuid.UUID {
+	var overlapErr *service.OverlapError
+	if !errors.As(err, &overlapErr) {
+		return nil
+	}
+	return &overlapErr.ConflictingID
+}
+
+// paramError — ошибка разбора параметра запроса field.
+func paramError(field, message string) error {
+	return &validator.FieldError{Field: field, Message: message}
+}
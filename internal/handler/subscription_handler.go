@@ -1,16 +1,21 @@
 package handler
 
 import (
-	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"go-subscriptions-service/internal/dto"
 	"go-subscriptions-service/internal/model"
 	"go-subscriptions-service/internal/service"
+	"go-subscriptions-service/pgk/currency"
+	"go-subscriptions-service/pgk/money"
 	"go-subscriptions-service/pgk/utils"
 	"go-subscriptions-service/pgk/validator"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,138 +32,539 @@ func NewSubscriptionHandler(s service.SubscriptionService) *SubscriptionHandler
 
 func (h *SubscriptionHandler) RegisterRouters(r *mux.Router) {
 	r.HandleFunc("/subscription/total_amount", h.GetTotalAmount).Methods("GET")
+	r.HandleFunc("/subscription/monthly_amount", h.GetMonthlyAmounts).Methods("GET")
+	r.HandleFunc("/subscription/upcoming", h.GetUpcoming).Methods("GET")
+	r.HandleFunc("/subscription/forecast", h.GetForecast).Methods("GET")
 	r.HandleFunc("/subscription", h.CreateSubscription).Methods("POST")
+	r.HandleFunc("/subscription/batch", h.CreateSubscriptionsBatch).Methods("POST")
 	r.HandleFunc("/subscription/{id}", h.GetSubscriptionsByID).Methods("GET")
 	r.HandleFunc("/subscription", h.GetAllSubscriptions).Methods("GET")
-	r.HandleFunc("/subscription/{id}", h.UpdateSubscription).Methods("PATCH")
+	r.HandleFunc("/subscription/{id}", h.PatchSubscription).Methods("PATCH")
+	r.HandleFunc("/subscription/{id}", h.UpdateSubscription).Methods("PUT")
 	r.HandleFunc("/subscription/{id}", h.DeleteSubscription).Methods("DELETE")
+	r.HandleFunc("/subscription/{id}/cancel", h.CancelSubscription).Methods("POST")
+	r.HandleFunc("/subscription/{id}/pause", h.PauseSubscription).Methods("POST")
+	r.HandleFunc("/subscription/{id}/resume", h.ResumeSubscription).Methods("POST")
+	r.HandleFunc("/subscription/{id}/pauses", h.GetPauses).Methods("GET")
+	r.HandleFunc("/subscription/{id}/prices", h.SchedulePriceChange).Methods("POST")
+	r.HandleFunc("/subscription/{id}/prices", h.GetPriceHistory).Methods("GET")
 }
 
 // GetTotalAmount godoc
 // @Summary Получить сумму подписок за период
-// @Description Считает сумму подписок за период пользователя в заданном диапазоне
+// @Description Считает сумму подписок пользователя за период: цена подписки начисляется в каждую дату списания по её циклу оплаты, попавшую в месяцы заданного диапазона
 // @Tags subscription
 // @Accept json
 // @Produce json
 // @Param user_id query string true "ID пользователя"
-// @Param from query string true "Дата начала периода (yyyy-mm-dd)"
-// @Param to query string true "Дата окончания периода (yyyy-mm-dd)"
+// @Param from query string true "Начало периода (YYYY-MM-DD или MM-YYYY, учитывается месяц)"
+// @Param to query string true "Конец периода (YYYY-MM-DD или MM-YYYY, учитывается месяц)"
 // @Param service_name query string false "Название сервиса (опционально)"
-// @Success 200 {object} map[string]int
-// @Failure 400 {string} string "Неверные параметры запроса"
-// @Failure 500 {string} string "Ошибка сервера"
+// @Param currency query string false "Валюта результата (RUB, USD, EUR; по умолчанию RUB)"
+// @Param group_by query string false "service_name — вернуть сумму и долю каждого сервиса, по убыванию суммы"
+// @Success 200 {object} dto.TotalAmountResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверные параметры запроса"
+// @Failure 422 {object} dto.ProblemResponse "Нет курса валюты для пересчёта суммы за месяц"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
 // @Router /subscription/total_amount [get]
 func (h *SubscriptionHandler) GetTotalAmount(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		log.Println("GetTotalAmount (handler) error: user_id is required")
-		http.Error(w, "user_id is required", http.StatusBadRequest)
+	q, err := parseAmountQuery(r)
+	if err != nil {
+		log.Println("GetTotalAmount (handler) error: parseAmountQuery failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
 		return
 	}
 
-	userIDUUID, err := uuid.Parse(userID)
+	byService, err := parseGroupBy(r)
 	if err != nil {
-		log.Println("GetTotalAmount (handler) error: uuid.Parse failed: ", err)
-		http.Error(w, "invalid user_id", http.StatusBadRequest)
+		log.Println("GetTotalAmount (handler) error: parseGroupBy failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
 		return
 	}
 
-	from := r.URL.Query().Get("from")
-	to := r.URL.Query().Get("to")
+	var total int64
+	var services []model.ServiceTotal
+
+	if byService {
+		services, err = h.service.GetTotalByService(*q)
+		if err != nil {
+			log.Println("GetTotalAmount (handler) error: failed to get totals by service: ", err)
+			writeError(w, r, err, "failed to get totals by service")
+			return
+		}
+
+		for _, st := range services {
+			total += st.Amount
+		}
+	} else {
+		total, err = h.service.GetTotalAmount(*q)
+		if err != nil {
+			log.Println("GetTotalAmount (handler) error: failed to get total amount: ", err)
+			writeError(w, r, err, "failed to get total amount")
+			return
+		}
+	}
 
-	if from == "" || to == "" {
-		log.Println("GetTotalAmount (handler) error: from and to are required")
-		http.Error(w, "from and to are required", http.StatusBadRequest)
+	res := dto.NewTotalAmountResponse(total, services, q.Currency)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+	log.Printf("GetTotalAmount (handler) success: user_id=%v, total=%s %s", q.UserID, res.TotalAmount, q.Currency)
+}
+
+// GetMonthlyAmounts godoc
+// @Summary Получить помесячную разбивку расходов
+// @Description Возвращает сумму начислений за каждый календарный месяц периода, опционально с разбивкой по сервисам
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param user_id query string true "ID пользователя"
+// @Param from query string true "Начало периода (YYYY-MM-DD или MM-YYYY, учитывается месяц)"
+// @Param to query string true "Конец периода (YYYY-MM-DD или MM-YYYY, учитывается месяц)"
+// @Param service_name query string false "Название сервиса (опционально)"
+// @Param currency query string false "Валюта результата (RUB, USD, EUR; по умолчанию RUB)"
+// @Param group_by query string false "Разбивка внутри месяца (service_name)"
+// @Success 200 {array} dto.MonthlyAmountResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверные параметры запроса"
+// @Failure 422 {object} dto.ProblemResponse "Нет курса валюты для пересчёта суммы за месяц"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/monthly_amount [get]
+func (h *SubscriptionHandler) GetMonthlyAmounts(w http.ResponseWriter, r *http.Request) {
+	q, err := parseAmountQuery(r)
+	if err != nil {
+		log.Println("GetMonthlyAmounts (handler) error: parseAmountQuery failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
 		return
 	}
 
-	fromDate, err := time.Parse("2006-01-02", from)
+	byService, err := parseGroupBy(r)
 	if err != nil {
-		log.Println("GetTotalAmount (handler) error: from time.Parse failed: ", err)
-		http.Error(w, "invalid from date", http.StatusBadRequest)
+		log.Println("GetMonthlyAmounts (handler) error: parseGroupBy failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
 		return
 	}
 
-	toDate, err := time.Parse("2006-01-02", to)
+	amounts, err := h.service.GetMonthlyAmounts(*q, byService)
 	if err != nil {
-		log.Println("GetTotalAmount (handler) error: to time.Parse failed: ", err)
-		http.Error(w, "invalid to date", http.StatusBadRequest)
+		log.Println("GetMonthlyAmounts (handler) error: failed to get monthly amounts: ", err)
+		writeError(w, r, err, "failed to get monthly amounts")
 		return
 	}
 
-	var servName *string
+	res := dto.NewMonthlyAmountResponses(amounts, q.Currency)
 
-	serviceName := r.URL.Query().Get("service_name")
-	if serviceName != "" {
-		servName = &serviceName
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+	log.Printf("GetMonthlyAmounts (handler) success: user_id=%v, months=%d", q.UserID, len(res))
+}
+
+// GetForecast godoc
+// @Summary Получить прогноз расходов
+// @Description Прогнозирует расходы пользователя по месяцам на N месяцев вперёд начиная с текущего с учётом end_date, циклов оплаты, пробных периодов и запланированных изменений цены
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param user_id query string true "ID пользователя"
+// @Param months query int false "Количество месяцев прогноза (1-60, по умолчанию 12)"
+// @Param service_name query string false "Название сервиса (опционально)"
+// @Param currency query string false "Валюта результата (RUB, USD, EUR; по умолчанию RUB)"
+// @Param group_by query string false "Разбивка внутри месяца (service_name)"
+// @Success 200 {object} dto.ForecastResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверные параметры запроса"
+// @Failure 422 {object} dto.ProblemResponse "Нет курса валюты для пересчёта суммы за месяц"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/forecast [get]
+func (h *SubscriptionHandler) GetForecast(w http.ResponseWriter, r *http.Request) {
+	q, err := parseUserFilter(r)
+	if err != nil {
+		log.Println("GetForecast (handler) error: parseUserFilter failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
 	}
 
-	res, err := h.service.GetTotalAmount(userIDUUID, servName, fromDate, toDate)
+	byService, err := parseGroupBy(r)
 	if err != nil {
-		log.Println("GetTotalAmount (handler) error: failed to get total amount: ", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Println("GetForecast (handler) error: parseGroupBy failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
 		return
 	}
 
-	type Res struct {
-		TotalAmount int `json:"total_amount"`
+	months := 12
+	if v := r.URL.Query().Get("months"); v != "" {
+		months, err = strconv.Atoi(v)
+		if err != nil || months < 1 || months > 60 {
+			log.Println("GetForecast (handler) error: invalid months: ", v)
+			writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("months", "invalid months (expected 1-60)"))
+			return
+		}
+	}
+
+	forecast, err := h.service.GetForecast(*q, months, byService)
+	if err != nil {
+		log.Println("GetForecast (handler) error: failed to get forecast: ", err)
+		writeError(w, r, err, "failed to get forecast")
+		return
 	}
 
+	res := dto.NewForecastResponse(forecast, q.Currency)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(Res{TotalAmount: res})
-	log.Printf("GetTotalAmount (handler) success: user_id=%v, total=%d", userIDUUID, res)
+	json.NewEncoder(w).Encode(res)
+	log.Printf("GetForecast (handler) success: user_id=%v, months=%d, total=%d", q.UserID, months, forecast.Total)
+}
+
+// GetUpcoming godoc
+// @Summary Получить ближайшие списания
+// @Description Возвращает подписки пользователя, ближайшее списание по которым попадает в ближайшие within_days дней, с датой и суммой списания
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param user_id query string true "ID пользователя"
+// @Param within_days query int false "Горизонт в днях (по умолчанию 7)"
+// @Success 200 {array} dto.UpcomingChargeResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверные параметры запроса"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/upcoming [get]
+func (h *SubscriptionHandler) GetUpcoming(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.URL.Query().Get("user_id"))
+	if err != nil {
+		log.Println("GetUpcoming (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("user_id", "invalid user_id"))
+		return
+	}
+
+	withinDays := 7
+	if v := r.URL.Query().Get("within_days"); v != "" {
+		withinDays, err = strconv.Atoi(v)
+		if err != nil || withinDays < 0 || withinDays > 366 {
+			log.Println("GetUpcoming (handler) error: invalid within_days: ", v)
+			writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("within_days", "invalid within_days (expected 0-366)"))
+			return
+		}
+	}
+
+	charges, err := h.service.GetUpcoming(userID, withinDays)
+	if err != nil {
+		log.Println("GetUpcoming (handler) error: failed to get upcoming charges: ", err)
+		writeError(w, r, err, "failed to get upcoming charges")
+		return
+	}
+
+	res := dto.NewUpcomingChargeResponses(charges)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+	log.Printf("GetUpcoming (handler) success: user_id=%v, charges=%d", userID, len(res))
+}
+
+func parseAmountQuery(r *http.Request) (*model.AmountFilter, error) {
+	q, err := parseUserFilter(r)
+	if err != nil {
+		return nil, err
+	}
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+
+	if from == "" {
+		log.Println("parseAmountQuery (handler) error: from is required")
+		return nil, paramError("from", "from is required")
+	}
+
+	if to == "" {
+		log.Println("parseAmountQuery (handler) error: to is required")
+		return nil, paramError("to", "to is required")
+	}
+
+	q.From, err = utils.ParseMonth(from)
+	if err != nil {
+		log.Println("parseAmountQuery (handler) error: from utils.ParseMonth failed: ", err)
+		return nil, paramError("from", "invalid from date (expected YYYY-MM-DD or MM-YYYY)")
+	}
+
+	q.To, err = utils.ParseMonth(to)
+	if err != nil {
+		log.Println("parseAmountQuery (handler) error: to utils.ParseMonth failed: ", err)
+		return nil, paramError("to", "invalid to date (expected YYYY-MM-DD or MM-YYYY)")
+	}
+
+	return q, nil
+}
+
+// parseUserFilter разбирает общие для отчётов параметры user_id,
+// service_name и currency.
+func parseUserFilter(r *http.Request) (*model.AmountFilter, error) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		log.Println("parseUserFilter (handler) error: user_id is required")
+		return nil, paramError("user_id", "user_id is required")
+	}
+
+	userIDUUID, err := uuid.Parse(userID)
+	if err != nil {
+		log.Println("parseUserFilter (handler) error: uuid.Parse failed: ", err)
+		return nil, paramError("user_id", "invalid user_id")
+	}
+
+	q := &model.AmountFilter{UserID: userIDUUID, Currency: currency.Default}
+
+	serviceName := r.URL.Query().Get("service_name")
+	if serviceName != "" {
+		q.ServiceName = &serviceName
+	}
+
+	if cur := r.URL.Query().Get("currency"); cur != "" {
+		if !currency.IsSupported(cur) {
+			log.Println("parseUserFilter (handler) error: unsupported currency: ", cur)
+			return nil, paramError("currency", fmt.Sprintf("unsupported currency (expected one of %s)", currency.SupportedList()))
+		}
+		q.Currency = cur
+	}
+
+	return q, nil
+}
+
+// parseGroupBy разбирает параметр group_by; поддерживается только service_name.
+func parseGroupBy(r *http.Request) (byService bool, err error) {
+	switch groupBy := r.URL.Query().Get("group_by"); groupBy {
+	case "":
+		return false, nil
+	case "service_name":
+		return true, nil
+	default:
+		log.Println("parseGroupBy (handler) error: unsupported group_by: ", groupBy)
+		return false, paramError("group_by", "invalid group_by (expected service_name)")
+	}
+}
+
+// parseFlag читает логический параметр запроса name (allow_overlap,
+// atomic); по умолчанию false.
+func parseFlag(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+
+	flag, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("parseFlag (handler) error: invalid %s: %v", name, v)
+		return false, paramError(name, fmt.Sprintf("invalid %s (expected true or false)", name))
+	}
+
+	return flag, nil
 }
 
 // CreateSubscription godoc
 // @Summary Создать подписку
-// @Description Создать новую подписку
+// @Description Создать новую подписку. billing_cycle: weekly, monthly (по умолчанию), quarterly, yearly или custom с длиной периода billing_interval в месяцах. Даты принимаются в формате YYYY-MM-DD или MM-YYYY (первое число месяца); день start_date задаёт день списания, в коротких месяцах — последний день месяца. Пробный период задаётся trial_end_date или trial_months, списания в нём стоят 0. end_date необязателен: без него подписка действует бессрочно
 // @Tags subscription
 // @Accept json
 // @Produce json
 // @Param request body dto.SubscriptionRequest true "Данные для создания подписки"
-// @Success 201 {object} model.Subscription
-// @Failure 400 {string} string "Неверные данные"
-// @Failure 500 {string} string "Ошибка сервера"
+// @Param allow_overlap query bool false "Разрешить пересечение с другими подписками на этот сервис (несколько мест)"
+// @Success 201 {object} dto.SubscriptionResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверные данные"
+// @Failure 409 {object} dto.ProblemResponse "Превышен бюджет пользователя или подписка пересекается с существующей"
+// @Failure 422 {object} dto.ProblemResponse "Нет курса валюты для пересчёта суммы за месяц"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
 // @Router /subscription [post]
 func (h *SubscriptionHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 	var req dto.SubscriptionRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Println("CreateSubscription (handler) error: json.NewDecoder failed: ", err)
-		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, errInvalidJSON)
 		return
 	}
 
 	if err := validator.ValidateCreateSubscriptionRequest(&req); err != nil {
 		log.Println("CreateSubscription (handler) error: validateCreateSubscriptionRequest failed: ", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
 		return
 	}
 
-	userID, _ := uuid.Parse(req.UserID)
-	startDate, _ := utils.ParseMonthYear(req.StartDate)
-	endDate, _ := utils.ParseMonthYear(req.EndDate)
-
-	sub := model.Subscription{
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		UserID:      userID,
-		StartDate:   startDate,
-		EndDate:     &endDate,
+	allowOverlap, err := parseFlag(r, "allow_overlap")
+	if err != nil {
+		log.Println("CreateSubscription (handler) error: parseFlag failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
 	}
 
+	sub := newSubscription(&req, allowOverlap)
+
 	if err := h.service.Create(&sub); err != nil {
 		log.Println("CreateSubscription (handler) error: failed to create subscription: ", err)
-		http.Error(w, "failed to create subscription", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to create subscription")
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(sub)
+	json.NewEncoder(w).Encode(dto.NewSubscriptionResponse(&sub))
 	log.Println("CreateSubscription (handler) success: subscription created")
 }
 
+// maxBatchSize — наибольшее число подписок в одном пакетном запросе.
+const maxBatchSize = 100
+
+// CreateSubscriptionsBatch godoc
+// @Summary Создать подписки пакетом
+// @Description Создаёт до 100 подписок одним запросом и возвращает результат по каждой: id созданной подписки или ошибку с code и field. Подписки проверяются так же, как при создании по одной, с учётом пересечений и бюджета внутри пакета. Без atomic создаются все прошедшие проверку подписки, с atomic=true — все или ни одной
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param request body []dto.SubscriptionRequest true "Подписки для создания"
+// @Param atomic query bool false "Создать все подписки или ни одной"
+// @Param allow_overlap query bool false "Разрешить пересечение с другими подписками на этот сервис (несколько мест)"
+// @Success 201 {object} dto.BatchResponse "Все подписки созданы"
+// @Success 200 {object} dto.BatchResponse "Часть подписок отклонена, остальные созданы"
+// @Failure 400 {object} dto.ProblemResponse "Неверное тело или параметры запроса"
+// @Failure 409 {object} dto.ProblemResponse "С atomic=true: подписка пересеклась с созданной конкурентным запросом"
+// @Failure 422 {object} dto.BatchResponse "С atomic=true: часть подписок отклонена, ни одна не создана"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/batch [post]
+func (h *SubscriptionHandler) CreateSubscriptionsBatch(w http.ResponseWriter, r *http.Request) {
+	var reqs []dto.SubscriptionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		log.Println("CreateSubscriptionsBatch (handler) error: json.NewDecoder failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, errInvalidJSON)
+		return
+	}
+
+	if len(reqs) == 0 || len(reqs) > maxBatchSize {
+		log.Println("CreateSubscriptionsBatch (handler) error: invalid batch size: ", len(reqs))
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, fmt.Errorf("batch must contain 1-%d subscriptions", maxBatchSize))
+		return
+	}
+
+	atomic, err := parseFlag(r, "atomic")
+	if err != nil {
+		log.Println("CreateSubscriptionsBatch (handler) error: parseFlag failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
+	}
+
+	allowOverlap, err := parseFlag(r, "allow_overlap")
+	if err != nil {
+		log.Println("CreateSubscriptionsBatch (handler) error: parseFlag failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
+	}
+
+	res := dto.BatchResponse{Atomic: atomic, Items: make([]dto.BatchItemResponse, len(reqs))}
+	subs := make([]*model.Subscription, len(reqs))
+	for i := range reqs {
+		if err := validator.ValidateCreateSubscriptionRequest(&reqs[i]); err != nil {
+			log.Printf("CreateSubscriptionsBatch (handler) error: subscription %d is invalid: %v", i, err)
+			res.Items[i] = batchItemError(i, http.StatusBadRequest, codeValidationFailed, err)
+			continue
+		}
+		sub := newSubscription(&reqs[i], allowOverlap)
+		subs[i] = &sub
+	}
+
+	errs, err := h.service.CreateBatch(subs, atomic)
+	if err != nil {
+		log.Println("CreateSubscriptionsBatch (handler) error: failed to create subscriptions: ", err)
+		writeError(w, r, err, "failed to create subscriptions")
+		return
+	}
+
+	for i, sub := range subs {
+		switch {
+		case sub == nil:
+			// Ошибка разбора уже записана.
+		case errs[i] != nil:
+			status, code := errorStatus(errs[i])
+			err := errs[i]
+			if status == http.StatusInternalServerError {
+				err = errors.New("failed to create subscription")
+			}
+			res.Items[i] = batchItemError(i, status, code, err)
+		case sub.ID == uuid.Nil:
+			res.Items[i] = batchItemError(i, http.StatusFailedDependency, codeBatchAborted, errors.New("not created: another subscription in the atomic batch was rejected"))
+		default:
+			res.Items[i] = dto.BatchItemResponse{Index: i, Status: http.StatusCreated, ID: &sub.ID}
+			res.Created++
+		}
+	}
+	res.Failed = len(reqs) - res.Created
+
+	status := http.StatusCreated
+	switch {
+	case res.Failed > 0 && atomic:
+		status = http.StatusUnprocessableEntity
+	case res.Failed > 0:
+		status = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+	log.Printf("CreateSubscriptionsBatch (handler) success: created=%d, failed=%d", res.Created, res.Failed)
+}
+
+// batchItemError — результат подписки пакета с ошибкой err; поле берётся
+// из validator.FieldError, как в writeProblem.
+func batchItemError(index, status int, code string, err error) dto.BatchItemResponse {
+	item := dto.BatchItemResponse{Index: index, Status: status, Code: code, Detail: err.Error()}
+
+	var fieldErr *validator.FieldError
+	if errors.As(err, &fieldErr) {
+		item.Field = fieldErr.Field
+	}
+	item.ConflictingID = conflictingID(err)
+
+	return item
+}
+
+// newSubscription переводит проверенное тело создания или замены в
+// подписку, подставляя значения по умолчанию.
+func newSubscription(req *dto.SubscriptionRequest, allowOverlap bool) model.Subscription {
+	userID, _ := uuid.Parse(req.UserID)
+	startDate, _ := utils.ParseDate(req.StartDate)
+	var endDate *time.Time
+	if req.EndDate != "" {
+		t, _ := utils.ParseDate(req.EndDate)
+		endDate = &t
+	}
+
+	if req.Currency == "" {
+		req.Currency = currency.Default
+	}
+	price, _ := money.Parse(req.Price.String(), req.Currency)
+
+	if req.BillingCycle == "" {
+		req.BillingCycle = model.BillingMonthly
+	}
+
+	var trialEndDate *time.Time
+	if req.TrialEndDate != "" {
+		t, _ := utils.ParseDate(req.TrialEndDate)
+		trialEndDate = &t
+	} else if req.TrialMonths > 0 {
+		t := utils.AddMonths(startDate, req.TrialMonths).AddDate(0, 0, -1)
+		trialEndDate = &t
+	}
+
+	return model.Subscription{
+		ServiceName:     req.ServiceName,
+		Price:           price,
+		BillingCycle:    req.BillingCycle,
+		BillingInterval: req.BillingInterval,
+		UserID:          userID,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		TrialEndDate:    trialEndDate,
+		AllowOverlap:    allowOverlap,
+	}
+}
+
 // GetSubscriptionsByID godoc
 // @Summary Получить подписку по ID
 // @Description Возвращает подписку по ID
@@ -166,10 +572,10 @@ func (h *SubscriptionHandler) CreateSubscription(w http.ResponseWriter, r *http.
 // @Accept json
 // @Produce json
 // @Param id path string true "ID подписки"
-// @Success 200 {object} model.Subscription
-// @Failure 400 {string} string "Неверный ID"
-// @Failure 404 {string} string "Подписка не найдена"
-// @Failure 500 {string} string "Ошибка сервера"
+// @Success 200 {object} dto.SubscriptionResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID"
+// @Failure 404 {object} dto.ProblemResponse "Подписка не найдена"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
 // @Router /subscription/{id} [get]
 func (h *SubscriptionHandler) GetSubscriptionsByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -178,64 +584,234 @@ func (h *SubscriptionHandler) GetSubscriptionsByID(w http.ResponseWriter, r *htt
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		log.Println("GetSubscriptionsByID (handler) error: uuid.Parse failed: ", err)
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("id", "invalid id"))
 		return
 	}
 
 	res, err := h.service.GetByID(id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			log.Println("GetSubscriptionsByID (handler) error: subscription not found: ", err)
-			http.Error(w, sql.ErrNoRows.Error(), http.StatusNotFound)
-			return
-		}
 		log.Println("GetSubscriptionsByID (handler) error: failed to get subscription by id: ", err)
-		http.Error(w, "failed to get subscription by id", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to get subscription by id")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(res)
+	json.NewEncoder(w).Encode(dto.NewSubscriptionResponse(res))
 	log.Println("GetSubscriptionsByID (handler) success: subscription found")
 }
 
 // GetAllSubscriptions godoc
-// @Summary Получить все подписки
-// @Description Возвращает список всех подписок
+// @Summary Получить список подписок
+// @Description Возвращает страницу подписок с фильтрами и сортировкой. Для следующей страницы передайте next_cursor из ответа в cursor с теми же параметрами; на последней странице next_cursor равен null
 // @Tags subscription
 // @Accept json
 // @Produce json
-// @Success 200 {array} model.Subscription
-// @Failure 500 {string} string "Ошибка сервера"
+// @Param user_id query string false "ID пользователя"
+// @Param service_name query string false "Название сервиса"
+// @Param active_at query string false "Месяц, в котором подписка действует (YYYY-MM-DD или MM-YYYY)"
+// @Param min_price query string false "Минимальная цена в валюте подписки (включительно)"
+// @Param max_price query string false "Максимальная цена в валюте подписки (включительно)"
+// @Param status query string false "Статус: trial, active, paused, cancelled или expired"
+// @Param sort query string false "Поле сортировки: start_date (по умолчанию), price или service_name"
+// @Param order query string false "Направление сортировки: asc (по умолчанию) или desc"
+// @Param limit query int false "Размер страницы (1-200, по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Success 200 {object} dto.SubscriptionListResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверные параметры запроса"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
 // @Router /subscription [get]
 func (h *SubscriptionHandler) GetAllSubscriptions(w http.ResponseWriter, r *http.Request) {
-	subscriptions, err := h.service.GetAll()
+	filter, err := parseSubscriptionFilter(r)
+	if err != nil {
+		log.Println("GetAllSubscriptions (handler) error: parseSubscriptionFilter failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
+	}
+
+	page, err := h.service.GetAll(*filter)
 	if err != nil {
 		log.Println("GetAllSubscriptions (handler) error: failed to get all subscriptions: ", err)
-		http.Error(w, "failed to get all subscriptions", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to get all subscriptions")
 		return
 	}
 
+	res := dto.SubscriptionListResponse{Items: dto.NewSubscriptionResponses(page.Items)}
+	if page.Next != nil {
+		next := encodeCursor(page.Next)
+		res.NextCursor = &next
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(subscriptions)
-	log.Println("GetAllSubscriptions (handler) success: all subscriptions found")
+	json.NewEncoder(w).Encode(res)
+	log.Printf("GetAllSubscriptions (handler) success: found %d subscriptions, has_next=%v", len(res.Items), res.NextCursor != nil)
+}
+
+// Размер страницы списка подписок.
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// parseSubscriptionFilter разбирает фильтры, сортировку и курсор списка
+// подписок.
+func parseSubscriptionFilter(r *http.Request) (*model.SubscriptionFilter, error) {
+	query := r.URL.Query()
+	filter := &model.SubscriptionFilter{Sort: model.SortStartDate, Limit: defaultPageLimit}
+
+	if v := query.Get("user_id"); v != "" {
+		userID, err := uuid.Parse(v)
+		if err != nil {
+			log.Println("parseSubscriptionFilter (handler) error: uuid.Parse failed: ", err)
+			return nil, paramError("user_id", "invalid user_id")
+		}
+		filter.UserID = &userID
+	}
+
+	if v := query.Get("service_name"); v != "" {
+		filter.ServiceName = &v
+	}
+
+	if v := query.Get("active_at"); v != "" {
+		month, err := utils.ParseMonth(v)
+		if err != nil {
+			log.Println("parseSubscriptionFilter (handler) error: active_at utils.ParseMonth failed: ", err)
+			return nil, paramError("active_at", "invalid active_at (expected YYYY-MM-DD or MM-YYYY)")
+		}
+		filter.ActiveAt = &month
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **int64
+	}{{"min_price", &filter.MinPrice}, {"max_price", &filter.MaxPrice}} {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		price, err := money.Parse(v, "")
+		if err != nil || price.Amount < 0 {
+			log.Printf("parseSubscriptionFilter (handler) error: invalid %s: %v", p.name, v)
+			return nil, paramError(p.name, fmt.Sprintf("invalid %s (expected decimal like 199.90)", p.name))
+		}
+		*p.dst = &price.Amount
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		log.Println("parseSubscriptionFilter (handler) error: min_price is greater than max_price")
+		return nil, paramError("min_price", "min_price must not be greater than max_price")
+	}
+
+	if v := query.Get("status"); v != "" {
+		if err := validator.ValidateStatus(v); err != nil {
+			log.Println("parseSubscriptionFilter (handler) error: validateStatus failed: ", err)
+			return nil, err
+		}
+		filter.Status = v
+	}
+
+	switch v := query.Get("sort"); v {
+	case "":
+	case model.SortStartDate, model.SortPrice, model.SortServiceName:
+		filter.Sort = v
+	default:
+		log.Println("parseSubscriptionFilter (handler) error: unsupported sort: ", v)
+		return nil, paramError("sort", "invalid sort (expected start_date, price or service_name)")
+	}
+
+	switch v := query.Get("order"); v {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		log.Println("parseSubscriptionFilter (handler) error: unsupported order: ", v)
+		return nil, paramError("order", "invalid order (expected asc or desc)")
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			log.Println("parseSubscriptionFilter (handler) error: invalid limit: ", v)
+			return nil, paramError("limit", fmt.Sprintf("invalid limit (expected 1-%d)", maxPageLimit))
+		}
+		filter.Limit = limit
+	}
+
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := decodeCursor(v)
+		if err != nil {
+			log.Println("parseSubscriptionFilter (handler) error: decodeCursor failed: ", err)
+			return nil, paramError("cursor", "invalid cursor")
+		}
+		if cursor.Sort != filter.Sort || cursor.Desc != filter.Desc {
+			log.Println("parseSubscriptionFilter (handler) error: cursor does not match sort")
+			return nil, paramError("cursor", "cursor does not match sort and order")
+		}
+		filter.After = cursor
+	}
+
+	return filter, nil
+}
+
+// cursorToken — содержимое курсора next_cursor; клиенту он передаётся
+// непрозрачной строкой base64.
+type cursorToken struct {
+	Sort  string    `json:"s"`
+	Desc  bool      `json:"d,omitempty"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func encodeCursor(c *model.SubscriptionCursor) string {
+	b, _ := json.Marshal(cursorToken{Sort: c.Sort, Desc: c.Desc, Value: c.Value, ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor разбирает курсор и проверяет, что значение поля сортировки
+// имеет его тип.
+func decodeCursor(s string) (*model.SubscriptionCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var t cursorToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+
+	switch t.Sort {
+	case model.SortStartDate:
+		_, err = time.Parse(utils.DateLayout, t.Value)
+	case model.SortPrice:
+		_, err = strconv.ParseInt(t.Value, 10, 64)
+	case model.SortServiceName:
+	default:
+		err = fmt.Errorf("unsupported sort %q", t.Sort)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SubscriptionCursor{Sort: t.Sort, Desc: t.Desc, Value: t.Value, ID: t.ID}, nil
 }
 
 // UpdateSubscription godoc
-// @Summary Обновить подписку
-// @Description Обновляет существующую подписку
+// @Summary Заменить подписку
+// @Description Заменяет подписку целиком: поля, которых нет в теле, получают значения по умолчанию, как при создании. Отмена и пауза не меняются
 // @Tags subscription
 // @Accept json
 // @Produce json
 // @Param id path string true "ID подписки"
 // @Param request body dto.SubscriptionRequest true "Оновленные данные подписки"
-// @Success 200 {object} model.Subscription
-// @Failure 400 {string} string "Неверный ID или тело запроса"
-// @Failure 404 {string} string "Подписка не найдена"
-// @Failure 500 {string} string "Ошибка сервера"
-// @Router /subscription/{id} [patch]
+// @Param allow_overlap query bool false "Разрешить пересечение с другими подписками на этот сервис (несколько мест)"
+// @Success 200 {object} dto.SubscriptionResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID или тело запроса"
+// @Failure 404 {object} dto.ProblemResponse "Подписка не найдена"
+// @Failure 409 {object} dto.ProblemResponse "Превышен бюджет пользователя, подписка пересекается с существующей или end_date меняется у отменённой либо истёкшей подписки"
+// @Failure 422 {object} dto.ProblemResponse "Нет курса валюты для пересчёта суммы за месяц"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/{id} [put]
 func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
@@ -243,7 +819,7 @@ func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		log.Println("UpdateSubscription (handler) error: uuid.Parse failed: ", err)
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("id", "invalid id"))
 		return
 	}
 
@@ -251,45 +827,175 @@ func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Println("UpdateSubscription (handler) error: json.NewDecoder failed: ", err)
-		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, errInvalidJSON)
 		return
 	}
 
 	if err := validator.ValidateCreateSubscriptionRequest(&req); err != nil {
 		log.Println("UpdateSubscription (handler) error: validateCreateSubscriptionRequest failed: ", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
 		return
 	}
 
-	userID, _ := uuid.Parse(req.UserID)
-	startDate, _ := utils.ParseMonthYear(req.StartDate)
-	endDate, _ := utils.ParseMonthYear(req.EndDate)
-
-	sub := model.Subscription{
-		ID:          id,
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		UserID:      userID,
-		StartDate:   startDate,
-		EndDate:     &endDate,
+	allowOverlap, err := parseFlag(r, "allow_overlap")
+	if err != nil {
+		log.Println("UpdateSubscription (handler) error: parseFlag failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
 	}
 
+	sub := newSubscription(&req, allowOverlap)
+	sub.ID = id
+
 	if err := h.service.Update(&sub); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			log.Println("UpdateSubscription (handler) error: subscription not found: ", err)
-			http.Error(w, sql.ErrNoRows.Error(), http.StatusNotFound)
-			return
-		}
 		log.Println("UpdateSubscription (handler) error: failed to update subscription: ", err)
-		http.Error(w, "failed to update subscription", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to update subscription")
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(sub)
+	json.NewEncoder(w).Encode(dto.NewSubscriptionResponse(&sub))
 	log.Println("UpdateSubscription (handler) success: subscription updated")
 }
 
+// PatchSubscription godoc
+// @Summary Частично обновить подписку
+// @Description Изменяет только поля, переданные в теле (JSON Merge Patch): null в end_date делает подписку бессрочной, null в trial_end_date снимает пробный период. Итоговая подписка проверяется так же, как при создании
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body dto.SubscriptionPatchRequest true "Изменяемые поля подписки"
+// @Param allow_overlap query bool false "Разрешить пересечение с другими подписками на этот сервис (без параметра не меняется)"
+// @Success 200 {object} dto.SubscriptionResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID или тело запроса"
+// @Failure 404 {object} dto.ProblemResponse "Подписка не найдена"
+// @Failure 409 {object} dto.ProblemResponse "Превышен бюджет пользователя, подписка пересекается с существующей или end_date меняется у отменённой либо истёкшей подписки"
+// @Failure 422 {object} dto.ProblemResponse "Нет курса валюты для пересчёта суммы за месяц"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/{id} [patch]
+func (h *SubscriptionHandler) PatchSubscription(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Println("PatchSubscription (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("id", "invalid id"))
+		return
+	}
+
+	var req dto.SubscriptionPatchRequest
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		log.Println("PatchSubscription (handler) error: json.NewDecoder failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, errInvalidJSON)
+		return
+	}
+
+	if err := validator.ValidateSubscriptionPatchRequest(&req); err != nil {
+		log.Println("PatchSubscription (handler) error: validateSubscriptionPatchRequest failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
+	}
+
+	patch := newSubscriptionPatch(&req)
+
+	if r.URL.Query().Get("allow_overlap") != "" {
+		allowOverlap, err := parseFlag(r, "allow_overlap")
+		if err != nil {
+			log.Println("PatchSubscription (handler) error: parseFlag failed: ", err)
+			writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+			return
+		}
+		patch.AllowOverlap = &allowOverlap
+	}
+
+	sub, err := h.service.Patch(id, patch)
+	if err != nil {
+		log.Println("PatchSubscription (handler) error: failed to patch subscription: ", err)
+		writeError(w, r, err, "failed to patch subscription")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.NewSubscriptionResponse(sub))
+	log.Println("PatchSubscription (handler) success: subscription patched")
+}
+
+// newSubscriptionPatch переводит проверенное тело PATCH в изменение
+// подписки. Цена без currency разбирается в валюте подписки: число знаков
+// после точки у поддерживаемых валют одинаковое.
+func newSubscriptionPatch(req *dto.SubscriptionPatchRequest) model.SubscriptionPatch {
+	var patch model.SubscriptionPatch
+
+	if req.ServiceName != nil {
+		var name string
+		json.Unmarshal(req.ServiceName, &name)
+		patch.ServiceName = &name
+	}
+	if req.Currency != nil {
+		var cur string
+		json.Unmarshal(req.Currency, &cur)
+		patch.Currency = &cur
+	}
+	if req.Price != nil {
+		var price json.Number
+		json.Unmarshal(req.Price, &price)
+		m, _ := money.Parse(price.String(), "")
+		patch.Price = &m.Amount
+	}
+	if req.BillingCycle != nil {
+		var cycle string
+		json.Unmarshal(req.BillingCycle, &cycle)
+		patch.BillingCycle = &cycle
+	}
+	if req.BillingInterval != nil {
+		var interval int
+		json.Unmarshal(req.BillingInterval, &interval)
+		patch.BillingInterval = &interval
+	}
+	if req.UserID != nil {
+		var s string
+		json.Unmarshal(req.UserID, &s)
+		userID, _ := uuid.Parse(s)
+		patch.UserID = &userID
+	}
+	if req.StartDate != nil {
+		patch.StartDate = patchDate(req.StartDate)
+	}
+	if req.EndDate != nil {
+		patch.EndDate = patchDate(req.EndDate)
+		patch.ClearEndDate = patch.EndDate == nil
+	}
+	if req.TrialEndDate != nil {
+		patch.TrialEndDate = patchDate(req.TrialEndDate)
+		patch.ClearTrial = patch.TrialEndDate == nil
+	}
+	if req.TrialMonths != nil {
+		var months int
+		json.Unmarshal(req.TrialMonths, &months)
+		patch.TrialMonths = &months
+	}
+
+	return patch
+}
+
+// patchDate разбирает дату из тела PATCH; null или пустая строка — nil.
+func patchDate(raw json.RawMessage) *time.Time {
+	var s string
+	json.Unmarshal(raw, &s)
+	if s == "" {
+		return nil
+	}
+	t, _ := utils.ParseDate(s)
+	return &t
+}
+
 // DeleteSubscription godoc
 // @Summary Удалить подписку
 // @Description Удаляет подписку по ID
@@ -298,9 +1004,9 @@ func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.
 // @Produce json
 // @Param id path string true "ID подписки"
 // @Success 204 {string} string ""
-// @Failure 400 {string} string "Неверный ID"
-// @Failure 404 {string} string "Подписка не найдена"
-// @Failure 500 {string} string "Ошибка сервера"
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID"
+// @Failure 404 {object} dto.ProblemResponse "Подписка не найдена"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
 // @Router /subscription/{id} [delete]
 func (h *SubscriptionHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -309,21 +1015,303 @@ func (h *SubscriptionHandler) DeleteSubscription(w http.ResponseWriter, r *http.
 	id, err := uuid.Parse(idStr)
 	if err != nil {
 		log.Println("DeleteSubscription (handler) error: uuid.Parse failed: ", err)
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("id", "invalid id"))
 		return
 	}
 
 	if err := h.service.Delete(id); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			log.Println("DeleteSubscription (handler) error: subscription not found: ", err)
-			http.Error(w, sql.ErrNoRows.Error(), http.StatusNotFound)
-			return
-		}
 		log.Println("DeleteSubscription (handler) error: failed to delete subscription: ", err)
-		http.Error(w, "failed to delete subscription", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to delete subscription")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 	log.Println("DeleteSubscription (handler) success: subscription deleted")
 }
+
+// CancelSubscription godoc
+// @Summary Отменить подписку
+// @Description Отменяет подписку с конца текущего оплаченного периода: end_date сдвигается на последний день периода, в который попадает сегодняшний день (или первый день effective_month). История и суммы за прошлые месяцы сохраняются
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body dto.CancelRequest false "Месяц отмены (YYYY-MM-DD или MM-YYYY) и причина"
+// @Success 200 {object} dto.SubscriptionResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID или тело запроса"
+// @Failure 404 {object} dto.ProblemResponse "Подписка не найдена"
+// @Failure 409 {object} dto.ProblemResponse "Отмена недопустима в текущем статусе подписки"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/{id}/cancel [post]
+func (h *SubscriptionHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Println("CancelSubscription (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("id", "invalid id"))
+		return
+	}
+
+	var req dto.CancelRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Println("CancelSubscription (handler) error: json.NewDecoder failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, errInvalidJSON)
+		return
+	}
+
+	if err := validator.ValidateCancelRequest(&req); err != nil {
+		log.Println("CancelSubscription (handler) error: validateCancelRequest failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
+	}
+
+	var effective time.Time
+	if req.EffectiveMonth != "" {
+		effective, _ = utils.ParseMonth(req.EffectiveMonth)
+	}
+
+	sub, err := h.service.Cancel(id, effective, req.Reason)
+	if err != nil {
+		log.Println("CancelSubscription (handler) error: failed to cancel subscription: ", err)
+		writeError(w, r, err, "failed to cancel subscription")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.NewSubscriptionResponse(sub))
+	log.Printf("CancelSubscription (handler) success: subscription %v ends on %v", sub.ID, sub.EndDate)
+}
+
+// SchedulePriceChange godoc
+// @Summary Запланировать изменение цены
+// @Description Задаёт новую цену подписки с указанного месяца (в прошлом или будущем). Начисления до этого месяца считаются по прежней цене
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body dto.PriceChangeRequest true "Новая цена и месяц, с которого она действует (YYYY-MM-DD или MM-YYYY)"
+// @Success 201 {object} dto.PriceChangeResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID или тело запроса"
+// @Failure 404 {object} dto.ProblemResponse "Подписка не найдена"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/{id}/prices [post]
+func (h *SubscriptionHandler) SchedulePriceChange(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Println("SchedulePriceChange (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("id", "invalid id"))
+		return
+	}
+
+	var req dto.PriceChangeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("SchedulePriceChange (handler) error: json.NewDecoder failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, errInvalidJSON)
+		return
+	}
+
+	if err := validator.ValidatePriceChangeRequest(&req); err != nil {
+		log.Println("SchedulePriceChange (handler) error: validatePriceChangeRequest failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
+	}
+
+	effectiveFrom, _ := utils.ParseMonth(req.EffectiveFrom)
+	// Валюту цены задаёт подписка, её подставит сервис.
+	price, _ := money.Parse(req.Price.String(), "")
+
+	change := model.PriceChange{
+		SubscriptionID: id,
+		Price:          price,
+		EffectiveFrom:  effectiveFrom,
+	}
+
+	if err := h.service.SchedulePriceChange(&change); err != nil {
+		log.Println("SchedulePriceChange (handler) error: failed to schedule price change: ", err)
+		writeError(w, r, err, "failed to schedule price change")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.NewPriceChangeResponse(&change))
+	log.Println("SchedulePriceChange (handler) success: price change scheduled")
+}
+
+// GetPriceHistory godoc
+// @Summary Получить историю цен подписки
+// @Description Возвращает все цены подписки с датами, с которых они действуют
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {array} dto.PriceChangeResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID"
+// @Failure 404 {object} dto.ProblemResponse "Подписка не найдена"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/{id}/prices [get]
+func (h *SubscriptionHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Println("GetPriceHistory (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("id", "invalid id"))
+		return
+	}
+
+	prices, err := h.service.GetPriceHistory(id)
+	if err != nil {
+		log.Println("GetPriceHistory (handler) error: failed to get price history: ", err)
+		writeError(w, r, err, "failed to get price history")
+		return
+	}
+
+	res := dto.NewPriceChangeResponses(prices)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+	log.Println("GetPriceHistory (handler) success: price history found")
+}
+
+// PauseSubscription godoc
+// @Summary Приостановить подписку
+// @Description Приостанавливает списания по подписке с сегодняшнего дня (или с первого дня effective_month). Списания на паузе не входят в суммы, прогнозы и ближайшие списания
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body dto.PauseRequest false "Месяц начала паузы (YYYY-MM-DD или MM-YYYY)"
+// @Success 200 {object} dto.SubscriptionResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID или тело запроса"
+// @Failure 404 {object} dto.ProblemResponse "Подписка не найдена"
+// @Failure 409 {object} dto.ProblemResponse "Пауза недопустима в текущем статусе подписки"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/{id}/pause [post]
+func (h *SubscriptionHandler) PauseSubscription(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Println("PauseSubscription (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("id", "invalid id"))
+		return
+	}
+
+	var req dto.PauseRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Println("PauseSubscription (handler) error: json.NewDecoder failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidJSON, errInvalidJSON)
+		return
+	}
+
+	if err := validator.ValidatePauseRequest(&req); err != nil {
+		log.Println("PauseSubscription (handler) error: validatePauseRequest failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, err)
+		return
+	}
+
+	var effective time.Time
+	if req.EffectiveMonth != "" {
+		effective, _ = utils.ParseMonth(req.EffectiveMonth)
+	}
+
+	sub, err := h.service.Pause(id, effective)
+	if err != nil {
+		log.Println("PauseSubscription (handler) error: failed to pause subscription: ", err)
+		writeError(w, r, err, "failed to pause subscription")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.NewSubscriptionResponse(sub))
+	log.Printf("PauseSubscription (handler) success: subscription %v paused from %v", sub.ID, sub.PausedFrom)
+}
+
+// ResumeSubscription godoc
+// @Summary Возобновить подписку
+// @Description Закрывает открытую паузу подписки: списания снова начисляются с сегодняшнего дня
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} dto.SubscriptionResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID"
+// @Failure 404 {object} dto.ProblemResponse "Подписка не найдена"
+// @Failure 409 {object} dto.ProblemResponse "Возобновление недопустимо в текущем статусе подписки"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/{id}/resume [post]
+func (h *SubscriptionHandler) ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Println("ResumeSubscription (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("id", "invalid id"))
+		return
+	}
+
+	sub, err := h.service.Resume(id)
+	if err != nil {
+		log.Println("ResumeSubscription (handler) error: failed to resume subscription: ", err)
+		writeError(w, r, err, "failed to resume subscription")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.NewSubscriptionResponse(sub))
+	log.Printf("ResumeSubscription (handler) success: subscription %v resumed", sub.ID)
+}
+
+// GetPauses godoc
+// @Summary Получить паузы подписки
+// @Description Возвращает все паузы подписки; у открытой паузы resumed_on равен null
+// @Tags subscription
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {array} dto.PauseResponse
+// @Failure 400 {object} dto.ProblemResponse "Неверный ID"
+// @Failure 404 {object} dto.ProblemResponse "Подписка не найдена"
+// @Failure 500 {object} dto.ProblemResponse "Ошибка сервера"
+// @Router /subscription/{id}/pauses [get]
+func (h *SubscriptionHandler) GetPauses(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Println("GetPauses (handler) error: uuid.Parse failed: ", err)
+		writeProblem(w, r, http.StatusBadRequest, codeValidationFailed, paramError("id", "invalid id"))
+		return
+	}
+
+	pauses, err := h.service.GetPauses(id)
+	if err != nil {
+		log.Println("GetPauses (handler) error: failed to get pauses: ", err)
+		writeError(w, r, err, "failed to get pauses")
+		return
+	}
+
+	res := dto.NewPauseResponses(pauses)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+	log.Println("GetPauses (handler) success: pauses found")
+}
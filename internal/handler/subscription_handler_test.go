@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/internal/service"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []model.SubscriptionCursor{
+		{Sort: model.SortStartDate, Value: "2024-03-10", ID: uuid.New()},
+		{Sort: model.SortPrice, Desc: true, Value: "19990", ID: uuid.New()},
+		{Sort: model.SortServiceName, Value: "Яндекс Плюс", ID: uuid.New()},
+	}
+
+	for _, want := range tests {
+		got, err := decodeCursor(encodeCursor(&want))
+		if err != nil {
+			t.Errorf("decodeCursor(encodeCursor(%+v)) error = %v", want, err)
+			continue
+		}
+		if *got != want {
+			t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v", want, *got)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	token := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not json", token("start_date")},
+		{"unsupported sort", token(`{"s":"user_id","v":"x","id":"` + uuid.NewString() + `"}`)},
+		{"start_date value is not a date", token(`{"s":"start_date","v":"03-2024","id":"` + uuid.NewString() + `"}`)},
+		{"price value is not a number", token(`{"s":"price","v":"199.90","id":"` + uuid.NewString() + `"}`)},
+		{"invalid id", token(`{"s":"price","v":"100","id":"42"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c, err := decodeCursor(tt.cursor); err == nil {
+				t.Errorf("decodeCursor() = %+v, want error", c)
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: bad input", service.ErrValidation), http.StatusBadRequest, codeValidationFailed},
+		{&service.NotFoundError{Entity: "subscription", ID: 1}, http.StatusNotFound, codeNotFound},
+		{fmt.Errorf("%w: stale", service.ErrPreconditionFailed), http.StatusPreconditionFailed, codePreconditionFailed},
+		{fmt.Errorf("%w: no rate", service.ErrRateUnavailable), http.StatusUnprocessableEntity, codeRateUnavailable},
+		{&service.OverlapError{}, http.StatusConflict, codeOverlap},
+		{service.ErrBudgetExists, http.StatusConflict, codeBudgetExists},
+		{errors.New("db is down"), http.StatusInternalServerError, codeInternal},
+	}
+
+	for _, tt := range tests {
+		status, code := errorStatus(tt.err)
+		if status != tt.status || code != tt.code {
+			t.Errorf("errorStatus(%v) = %d %s, want %d %s", tt.err, status, code, tt.status, tt.code)
+		}
+	}
+}
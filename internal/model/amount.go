@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AmountFilter — фильтры отчётов по расходам пользователя; суммы
+// пересчитываются в Currency и хранятся в её минимальных единицах
+// (копейках, центах). ExcludeID исключает подписку из расчёта.
+type AmountFilter struct {
+	UserID      uuid.UUID
+	ServiceName *string
+	From        time.Time
+	To          time.Time
+	Currency    string
+	ExcludeID   *uuid.UUID
+}
+
+// MonthlyCharge — сумма начислений по сервису в одной валюте за месяц.
+// Trial отмечает списания, попавшие в пробный период (их сумма 0).
+type MonthlyCharge struct {
+	Month       time.Time
+	ServiceName string
+	Currency    string
+	Trial       bool
+	Amount      int64
+}
+
+// ServiceAmount и MonthlyAmount помечаются Trial, если все их списания
+// пришлись на пробный период.
+type ServiceAmount struct {
+	ServiceName string
+	Amount      int64
+	Trial       bool
+}
+
+type MonthlyAmount struct {
+	Month    time.Time
+	Amount   int64
+	Trial    bool
+	Services []ServiceAmount
+}
+
+// ServiceTotal — сумма по сервису за период и её доля в общей сумме (0..1).
+type ServiceTotal struct {
+	ServiceName string
+	Amount      int64
+	Share       float64
+}
+
+type Forecast struct {
+	Months []MonthlyAmount
+	Total  int64
+}
+
+// UpcomingCharge — ближайшее списание по подписке.
+type UpcomingCharge struct {
+	SubscriptionID uuid.UUID
+	ServiceName    string
+	ChargeDate     time.Time
+	Amount         int64
+	Currency       string
+	Trial          bool
+}
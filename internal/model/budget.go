@@ -0,0 +1,27 @@
+package model
+
+import (
+	"go-subscriptions-service/pgk/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Budget — месячный бюджет пользователя на подписки. При Enforce
+// изменения подписок, выводящие будущие месяцы за бюджет, отклоняются.
+type Budget struct {
+	UserID    uuid.UUID
+	Amount    money.Money
+	Enforce   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// BudgetMonth сравнивает расходы за месяц с бюджетом в валюте бюджета.
+type BudgetMonth struct {
+	Month      time.Time
+	Spent      int64
+	Budget     int64
+	Currency   string
+	OverBudget bool
+}
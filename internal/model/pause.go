@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Pause — интервал [PausedFrom, ResumedOn), в который списания по подписке
+// не начисляются. ResumedOn == nil — пауза открыта.
+type Pause struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	PausedFrom     time.Time
+	ResumedOn      *time.Time
+	CreatedAt      time.Time
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"go-subscriptions-service/pgk/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceChange — цена подписки, действующая с EffectiveFrom до следующего
+// изменения.
+type PriceChange struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	Price          money.Money
+	EffectiveFrom  time.Time
+	CreatedAt      time.Time
+}
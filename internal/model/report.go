@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// ServiceRevenueRow — строка отчёта по выручке сервиса за месяц в одной
+// валюте; Currency пуст, если в месяце не было списаний.
+type ServiceRevenueRow struct {
+	ServiceName         string
+	Month               time.Time
+	Subscribers         int
+	ActiveSubscriptions int
+	Currency            string
+	Revenue             int64
+}
+
+type RevenueMonth struct {
+	Month               time.Time
+	Subscribers         int
+	ActiveSubscriptions int
+	Revenue             int64
+}
+
+// ServiceRevenue — показатели сервиса по всем пользователям помесячно.
+type ServiceRevenue struct {
+	ServiceName  string
+	Months       []RevenueMonth
+	TotalRevenue int64
+}
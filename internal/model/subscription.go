@@ -1,16 +1,125 @@
 package model
 
 import (
+	"go-subscriptions-service/pgk/money"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Циклы списаний подписки. Для BillingCustom длина периода в месяцах
+// задаётся Subscription.BillingInterval.
+const (
+	BillingWeekly    = "weekly"
+	BillingMonthly   = "monthly"
+	BillingQuarterly = "quarterly"
+	BillingYearly    = "yearly"
+	BillingCustom    = "custom"
+)
+
+// Статусы жизненного цикла подписки. Статус не хранится, а вычисляется
+// сервисом по датам, паузе и отмене.
+const (
+	StatusTrial     = "trial"
+	StatusActive    = "active"
+	StatusPaused    = "paused"
+	StatusCancelled = "cancelled"
+	StatusExpired   = "expired"
+)
+
+// Поля сортировки списка подписок. При равных значениях подписки
+// упорядочиваются по id, чтобы страницы не пересекались.
+const (
+	SortStartDate   = "start_date"
+	SortPrice       = "price"
+	SortServiceName = "service_name"
+)
+
+// SubscriptionFilter — фильтры, сортировка и страница списка подписок;
+// пустое поле не фильтрует.
+type SubscriptionFilter struct {
+	UserID      *uuid.UUID
+	ServiceName *string
+	// ActiveAt — первое число месяца, в котором подписка действует хотя бы
+	// один день.
+	ActiveAt *time.Time
+	// MinPrice и MaxPrice — границы цены включительно, в минимальных
+	// единицах валюты подписки.
+	MinPrice *int64
+	MaxPrice *int64
+	Status   string
+	// At — дата, на которую вычисляется Status; заполняет сервис.
+	At    time.Time
+	Sort  string
+	Desc  bool
+	Limit int
+	// After — курсор последней подписки предыдущей страницы.
+	After *SubscriptionCursor
+}
+
+// SubscriptionCursor — позиция в списке подписок: значение поля сортировки
+// Sort и id последней выданной подписки.
+type SubscriptionCursor struct {
+	Sort  string
+	Desc  bool
+	Value string
+	ID    uuid.UUID
+}
+
+// SubscriptionPage — страница списка подписок; Next == nil на последней.
+type SubscriptionPage struct {
+	Items []Subscription
+	Next  *SubscriptionCursor
+}
+
 type Subscription struct {
-	ID          uuid.UUID
-	ServiceName string
-	Price       int
-	UserID      uuid.UUID
-	StartDate   time.Time
-	EndDate     *time.Time
+	ID              uuid.UUID
+	ServiceName     string
+	Price           money.Money
+	BillingCycle    string
+	BillingInterval int
+	// BillingAnchorDay — день месяца, в который идут списания (день
+	// StartDate); в коротких месяцах — последний день месяца.
+	BillingAnchorDay int
+	UserID           uuid.UUID
+	StartDate        time.Time
+	EndDate          *time.Time
+	TrialEndDate     *time.Time
+	// AllowOverlap разрешает подписке пересекаться по датам с другими
+	// подписками пользователя на тот же сервис (несколько мест).
+	AllowOverlap bool
+	// CancelledAt и CancelReason заполняются при отмене подписки; EndDate
+	// при этом сдвигается на конец оплаченного периода.
+	CancelledAt  *time.Time
+	CancelReason *string
+	// PausedFrom — начало открытой паузы (nil, если её нет). Списания с этой
+	// даты не начисляются до возобновления.
+	PausedFrom *time.Time
+	// Status и остальные вычисляемые поля ниже заполняет сервис на момент
+	// ответа.
+	Status                string
+	NormalizedMonthlyCost money.Money
+	InTrial               bool
+	Paused                bool
+}
+
+// SubscriptionPatch — частичное изменение подписки по правилам JSON Merge
+// Patch: nil-поле не меняется, ClearEndDate и ClearTrial соответствуют null
+// в end_date и trial_end_date.
+type SubscriptionPatch struct {
+	ServiceName *string
+	// Price — новая цена в минимальных единицах валюты подписки.
+	Price           *int64
+	Currency        *string
+	BillingCycle    *string
+	BillingInterval *int
+	UserID          *uuid.UUID
+	StartDate       *time.Time
+	EndDate         *time.Time
+	ClearEndDate    bool
+	TrialEndDate    *time.Time
+	// TrialMonths задаёт пробный период от (новой) StartDate; 0 — без него.
+	TrialMonths  *int
+	ClearTrial   bool
+	AllowOverlap *bool
 }
@@ -0,0 +1,119 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-subscriptions-service/internal/model"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+type BudgetRepository interface {
+	Create(budget *model.Budget) error
+	GetByUserID(userID uuid.UUID) (*model.Budget, error)
+	Update(budget *model.Budget) error
+	Delete(userID uuid.UUID) error
+}
+
+type budgetRepo struct {
+	db *sql.DB
+}
+
+func NewBudgetRepo(db *sql.DB) BudgetRepository {
+	return &budgetRepo{db: db}
+}
+
+func (r *budgetRepo) Create(budget *model.Budget) error {
+	log.Printf("Create (budget repo): inserting budget for user_id=%v", budget.UserID)
+
+	err := r.db.QueryRow(
+		`
+		INSERT INTO budgets (user_id, amount, currency, enforce)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+		`, budget.UserID, budget.Amount.Amount, budget.Amount.Currency, budget.Enforce).Scan(&budget.CreatedAt, &budget.UpdatedAt)
+	if err != nil {
+		log.Printf("Create (budget repo) error: %v", err)
+		if isUniqueViolation(err) {
+			return ErrBudgetExists
+		}
+		return fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	log.Printf("Create (budget repo) success: created budget for user_id=%v", budget.UserID)
+	return nil
+}
+
+func (r *budgetRepo) GetByUserID(userID uuid.UUID) (*model.Budget, error) {
+	log.Printf("GetByUserID (budget repo): retrieving budget for user_id=%v", userID)
+	var b model.Budget
+
+	err := r.db.QueryRow(
+		`
+		SELECT user_id, amount, currency, enforce, created_at, updated_at
+		FROM budgets
+		WHERE user_id = $1
+		`, userID).Scan(&b.UserID, &b.Amount.Amount, &b.Amount.Currency, &b.Enforce, &b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("GetByUserID (budget repo) not found: %v", err)
+			return nil, sql.ErrNoRows
+		}
+		log.Printf("GetByUserID (budget repo) error: %v", err)
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	log.Printf("GetByUserID (budget repo) success: budget found for user_id=%v", b.UserID)
+	return &b, nil
+}
+
+func (r *budgetRepo) Update(budget *model.Budget) error {
+	log.Printf("Update (budget repo): updating budget for user_id=%v", budget.UserID)
+
+	err := r.db.QueryRow(
+		`
+		UPDATE budgets
+		SET amount = $2, currency = $3, enforce = $4, updated_at = now()
+		WHERE user_id = $1
+		RETURNING created_at, updated_at
+		`, budget.UserID, budget.Amount.Amount, budget.Amount.Currency, budget.Enforce).Scan(&budget.CreatedAt, &budget.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Update (budget repo) not found: %v", err)
+			return sql.ErrNoRows
+		}
+		log.Printf("Update (budget repo) error: %v", err)
+		return fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	log.Printf("Update (budget repo) success: updated budget for user_id=%v", budget.UserID)
+	return nil
+}
+
+func (r *budgetRepo) Delete(userID uuid.UUID) error {
+	log.Printf("Delete (budget repo): deleting budget for user_id=%v", userID)
+	res, err := r.db.Exec(
+		`
+		DELETE FROM budgets
+		WHERE user_id = $1
+		`, userID)
+	if err != nil {
+		log.Printf("Delete (budget repo) error: %v", err)
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("Delete (budget repo) rows affected error: %v", err)
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	if n == 0 {
+		log.Printf("Delete (budget repo) not found: user_id=%v", userID)
+		return sql.ErrNoRows
+	}
+
+	log.Printf("Delete (budget repo) success: deleted budget for user_id=%v", userID)
+	return nil
+}
@@ -0,0 +1,33 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrOverlap — запись нарушает ограничение subscriptions_no_overlap:
+// у пользователя уже есть подписка на этот сервис на пересекающиеся даты.
+var ErrOverlap = errors.New("subscription overlaps an existing subscription")
+
+// ErrPauseExists — у подписки уже есть открытая пауза.
+var ErrPauseExists = errors.New("subscription already has an open pause")
+
+// ErrBudgetExists — у пользователя уже есть бюджет.
+var ErrBudgetExists = errors.New("budget already exists")
+
+// Коды ошибок PostgreSQL для нарушений UNIQUE и EXCLUDE.
+const (
+	uniqueViolation    = "23505"
+	exclusionViolation = "23P01"
+)
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+func isExclusionViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == exclusionViolation
+}
@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/pgk/utils"
+	"log"
+	"time"
+)
+
+type ReportRepository interface {
+	GetServiceRevenue(from, to time.Time) ([]model.ServiceRevenueRow, error)
+}
+
+type reportRepo struct {
+	db *sql.DB
+}
+
+func NewReportRepo(db *sql.DB) ReportRepository {
+	return &reportRepo{db: db}
+}
+
+// GetServiceRevenue по всем пользователям считает для каждого service_name
+// и месяца между from и to число подписчиков и активных подписок (активных
+// хотя бы один день месяца) и выручку по валютам.
+func (r *reportRepo) GetServiceRevenue(from, to time.Time) ([]model.ServiceRevenueRow, error) {
+	log.Printf("GetServiceRevenue (report repo): getting revenue from=%v, to=%v", from, to)
+
+	rows, err := r.db.Query(`
+	WITH months AS (
+		SELECT generate_series($1::date, $2::date, interval '1 month')::date AS month
+	),
+	active AS (
+		SELECT s.service_name, m.month, COUNT(DISTINCT s.user_id) AS subscribers, COUNT(*) AS active_subscriptions
+		FROM subscriptions s
+		JOIN months m ON s.start_date < m.month + interval '1 month'
+			AND (s.end_date IS NULL OR s.end_date >= m.month)
+		GROUP BY s.service_name, m.month
+	),
+	revenue AS (
+		SELECT s.service_name, date_trunc('month', c.charge_date)::date AS month, p.currency, SUM(p.price)::bigint AS amount`+chargesFrom+`
+		GROUP BY 1, 2, 3
+	)
+	SELECT a.service_name, a.month, a.subscribers, a.active_subscriptions, r.currency, COALESCE(r.amount, 0)
+	FROM active a
+	LEFT JOIN revenue r ON r.service_name = a.service_name AND r.month = a.month
+	ORDER BY a.service_name, a.month, r.currency
+	`, utils.StartOfMonth(from), utils.EndOfMonth(to))
+	if err != nil {
+		log.Printf("GetServiceRevenue (report repo) query error: %v", err)
+		return nil, fmt.Errorf("failed to get service revenue: %w", err)
+	}
+	defer rows.Close()
+
+	var report []model.ServiceRevenueRow
+
+	for rows.Next() {
+		var row model.ServiceRevenueRow
+		var cur sql.NullString
+
+		err = rows.Scan(&row.ServiceName, &row.Month, &row.Subscribers, &row.ActiveSubscriptions, &cur, &row.Revenue)
+		if err != nil {
+			log.Printf("GetServiceRevenue (report repo) scan error: %v", err)
+			return nil, fmt.Errorf("failed to scan service revenue: %w", err)
+		}
+		row.Currency = cur.String
+		report = append(report, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("GetServiceRevenue (report repo) rows error: %v", err)
+		return nil, fmt.Errorf("failed to get service revenue: %w", err)
+	}
+
+	log.Printf("GetServiceRevenue (report repo) success: found %d rows", len(report))
+	return report, nil
+}
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/pgk/utils"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,13 +16,110 @@ import (
 
 type SubscriptionRepository interface {
 	Create(subscription *model.Subscription) error
+	// CreateBatch вставляет подписки и их начальные цены многострочными
+	// INSERT в одной транзакции: сохраняются все подписки или ни одна. ID
+	// подписок заполняются.
+	CreateBatch(subscriptions []*model.Subscription) error
 	GetByID(id uuid.UUID) (*model.Subscription, error)
-	GetAll() ([]model.Subscription, error)
-	Update(subscription *model.Subscription) error
+	// GetAll возвращает страницу подписок, отобранных и отсортированных по
+	// filter; следующая страница начинается после filter.After.
+	GetAll(filter model.SubscriptionFilter) (*model.SubscriptionPage, error)
+	GetActiveByUser(userID uuid.UUID, at time.Time) ([]model.Subscription, error)
+	// Update блокирует подписку (SELECT ... FOR UPDATE), передаёт её в apply
+	// и сохраняет изменённую apply подписку в той же транзакции. Ошибка
+	// apply откатывает транзакцию и возвращается без изменений.
+	Update(id uuid.UUID, apply func(subscription *model.Subscription) error) (*model.Subscription, error)
 	Delete(id uuid.UUID) error
-	GetTotalAmount(userID uuid.UUID, serviceName *string, from, to time.Time) (int, error)
+	GetMonthlyCharges(filter model.AmountFilter) ([]model.MonthlyCharge, error)
+	AddPriceChange(change *model.PriceChange) error
+	GetPriceHistory(subscriptionID uuid.UUID) ([]model.PriceChange, error)
+	// GetOverlapping возвращает другую подписку пользователя на тот же сервис
+	// без allow_overlap, даты которой пересекаются с subscription, или
+	// sql.ErrNoRows, если такой нет.
+	GetOverlapping(subscription *model.Subscription) (*model.Subscription, error)
+	// Cancel сохраняет end_date, cancelled_at и cancel_reason подписки.
+	Cancel(subscription *model.Subscription) error
+	AddPause(pause *model.Pause) error
+	// Resume закрывает открытую паузу подписки датой on (не раньше начала
+	// паузы); sql.ErrNoRows, если открытой паузы нет.
+	Resume(subscriptionID uuid.UUID, on time.Time) (*model.Pause, error)
+	GetPauses(subscriptionID uuid.UUID) ([]model.Pause, error)
 }
 
+// subscriptionColumns выбирает подписку из таблицы subscriptions (без
+// псевдонима) вместе с началом её открытой паузы.
+const subscriptionColumns = `id, service_name, price, currency, billing_cycle, billing_interval, billing_anchor_day, user_id, start_date, end_date, trial_end_date, allow_overlap, cancelled_at, cancel_reason,
+	(SELECT paused_from FROM subscription_pauses WHERE subscription_id = subscriptions.id AND resumed_on IS NULL)`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSubscription(row rowScanner, s *model.Subscription) error {
+	return row.Scan(&s.ID, &s.ServiceName, &s.Price.Amount, &s.Price.Currency, &s.BillingCycle, &s.BillingInterval, &s.BillingAnchorDay, &s.UserID, &s.StartDate, &s.EndDate, &s.TrialEndDate, &s.AllowOverlap, &s.CancelledAt, &s.CancelReason, &s.PausedFrom)
+}
+
+// billingMonthsSQL — длина периода оплаты подписки s в месяцах (для всех
+// циклов, кроме weekly).
+const billingMonthsSQL = `CASE s.billing_cycle
+			WHEN 'monthly' THEN 1
+			WHEN 'quarterly' THEN 3
+			WHEN 'yearly' THEN 12
+			ELSE s.billing_interval
+		END`
+
+// chargesFrom разворачивает каждую подписку в строки c.charge_date — даты
+// списаний внутри окна [$1, $2] — с ценой p.price в валюте p.currency,
+// действующими на дату списания по subscription_prices. Списания до
+// trial_end_date включительно помечаются t.trial и стоят 0. Списания идут
+// от start_date с шагом billing_cycle и прекращаются после end_date (NULL —
+// подписка ещё действует); списания, попавшие на паузу из
+// subscription_pauses, пропускаются.
+// Помесячные списания идут в billing_anchor_day, а в коротких месяцах — в
+// последний день месяца, поэтому 31-е число в феврале не сдвигает
+// следующие списания.
+const chargesFrom = `
+	FROM subscriptions s
+	CROSS JOIN LATERAL generate_series(0, CASE
+		WHEN s.billing_cycle = 'weekly' THEN ($2::date - s.start_date) / 7
+		ELSE ((date_part('year', $2::date) - date_part('year', s.start_date)) * 12
+			+ date_part('month', $2::date) - date_part('month', s.start_date))::int / ` + billingMonthsSQL + `
+	END) AS k
+	CROSS JOIN LATERAL (
+		SELECT (date_trunc('month', s.start_date) + make_interval(months => k * ` + billingMonthsSQL + `))::date AS month_start
+	) cm
+	CROSS JOIN LATERAL (
+		SELECT CASE
+			WHEN s.billing_cycle = 'weekly' THEN s.start_date + k * 7
+			ELSE cm.month_start + LEAST(s.billing_anchor_day,
+				date_part('day', cm.month_start + interval '1 month' - interval '1 day')::int) - 1
+		END AS charge_date
+	) c
+	CROSS JOIN LATERAL (
+		SELECT s.trial_end_date IS NOT NULL AND c.charge_date <= s.trial_end_date AS trial
+	) t
+	LEFT JOIN LATERAL (
+		SELECT sp.price, sp.currency
+		FROM subscription_prices sp
+		WHERE sp.subscription_id = s.id AND sp.effective_from <= c.charge_date
+		ORDER BY sp.effective_from DESC
+		LIMIT 1
+	) h ON true
+	CROSS JOIN LATERAL (
+		SELECT CASE WHEN t.trial THEN 0 ELSE COALESCE(h.price, s.price) END AS price,
+			COALESCE(h.currency, s.currency) AS currency
+	) p
+	WHERE c.charge_date BETWEEN $1 AND $2
+	AND c.charge_date >= s.start_date
+	AND (s.end_date IS NULL OR c.charge_date <= s.end_date)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM subscription_pauses ps
+		WHERE ps.subscription_id = s.id
+		AND c.charge_date >= ps.paused_from
+		AND (ps.resumed_on IS NULL OR c.charge_date < ps.resumed_on)
+	)`
+
 type subscriptionRepo struct {
 	db *sql.DB
 }
@@ -31,53 +131,274 @@ func NewSubscriptionRepo(db *sql.DB) SubscriptionRepository {
 func (r *subscriptionRepo) Create(subscription *model.Subscription) error {
 	log.Printf("Create  (repo): inserting subscription for user_id=%v, service_name=%v", subscription.UserID, subscription.ServiceName)
 
-	err := r.db.QueryRow(
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Printf("Create (repo) transaction error: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	err = tx.QueryRow(
 		`
-		INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO subscriptions (service_name, price, currency, billing_cycle, billing_interval, billing_anchor_day, user_id, start_date, end_date, trial_end_date, allow_overlap)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
-		`, subscription.ServiceName, subscription.Price, subscription.UserID, subscription.StartDate, subscription.EndDate).Scan(&subscription.ID)
+		`, subscription.ServiceName, subscription.Price.Amount, subscription.Price.Currency, subscription.BillingCycle, subscription.BillingInterval, subscription.BillingAnchorDay, subscription.UserID, subscription.StartDate, subscription.EndDate, subscription.TrialEndDate, subscription.AllowOverlap).Scan(&subscription.ID)
 	if err != nil {
 		log.Printf("Create (repo) error: %v", err)
-		return fmt.Errorf("failed to create subscription: %v", err)
+		tx.Rollback()
+		if isExclusionViolation(err) {
+			return ErrOverlap
+		}
+		return fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`
+		INSERT INTO subscription_prices (subscription_id, price, currency, effective_from)
+		VALUES ($1, $2, $3, $4)
+		`, subscription.ID, subscription.Price.Amount, subscription.Price.Currency, subscription.StartDate)
+	if err != nil {
+		log.Printf("Create (repo) price error: %v", err)
+		tx.Rollback()
+		return fmt.Errorf("failed to create subscription price: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Create (repo) commit error: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	log.Printf("Create (repo) success: created subscription with id=%v", subscription.ID)
 	return nil
 }
 
+func (r *subscriptionRepo) CreateBatch(subscriptions []*model.Subscription) error {
+	log.Printf("CreateBatch (repo): inserting %d subscriptions", len(subscriptions))
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	// id генерируются заранее: порядок строк RETURNING у многострочного
+	// INSERT не гарантирован.
+	ids := make([]uuid.UUID, len(subscriptions))
+	var rows, priceRows []string
+	var args, priceArgs []interface{}
+	for i, s := range subscriptions {
+		ids[i] = uuid.New()
+		rows = append(rows, placeholders(len(args), 12))
+		args = append(args, ids[i], s.ServiceName, s.Price.Amount, s.Price.Currency, s.BillingCycle, s.BillingInterval, s.BillingAnchorDay, s.UserID, s.StartDate, s.EndDate, s.TrialEndDate, s.AllowOverlap)
+		priceRows = append(priceRows, placeholders(len(priceArgs), 4))
+		priceArgs = append(priceArgs, ids[i], s.Price.Amount, s.Price.Currency, s.StartDate)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Printf("CreateBatch (repo) transaction error: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`
+		INSERT INTO subscriptions (id, service_name, price, currency, billing_cycle, billing_interval, billing_anchor_day, user_id, start_date, end_date, trial_end_date, allow_overlap)
+		VALUES `+strings.Join(rows, ", "), args...)
+	if err != nil {
+		log.Printf("CreateBatch (repo) error: %v", err)
+		tx.Rollback()
+		if isExclusionViolation(err) {
+			return ErrOverlap
+		}
+		return fmt.Errorf("failed to create subscriptions: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`
+		INSERT INTO subscription_prices (subscription_id, price, currency, effective_from)
+		VALUES `+strings.Join(priceRows, ", "), priceArgs...)
+	if err != nil {
+		log.Printf("CreateBatch (repo) price error: %v", err)
+		tx.Rollback()
+		return fmt.Errorf("failed to create subscription prices: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("CreateBatch (repo) commit error: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for i, s := range subscriptions {
+		s.ID = ids[i]
+	}
+
+	log.Printf("CreateBatch (repo) success: created %d subscriptions", len(subscriptions))
+	return nil
+}
+
+// placeholders возвращает строку VALUES из n параметров, начиная с
+// $(offset+1): "($1, $2, $3)".
+func placeholders(offset, n int) string {
+	params := make([]string, n)
+	for i := range params {
+		params[i] = "$" + strconv.Itoa(offset+i+1)
+	}
+	return "(" + strings.Join(params, ", ") + ")"
+}
+
 func (r *subscriptionRepo) GetByID(id uuid.UUID) (*model.Subscription, error) {
 	log.Printf("GetByID (repo): retrieving subscription for id=%v", id)
 	var s model.Subscription
 
-	err := r.db.QueryRow(
+	err := scanSubscription(r.db.QueryRow(
 		`
-		SELECT id, service_name, price, user_id, start_date, end_date
+		SELECT `+subscriptionColumns+`
 		FROM subscriptions
 		WHERE id = $1
-		`, id).Scan(&s.ID, &s.ServiceName, &s.Price, &s.UserID, &s.StartDate, &s.EndDate)
+		`, id), &s)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("GetByID (repo) not found: %v", err)
 			return nil, sql.ErrNoRows
 		}
 		log.Printf("GetByID (repo) error: %v", err)
-		return nil, fmt.Errorf("failed to get subscription by id: %v", err)
+		return nil, fmt.Errorf("failed to get subscription by id: %w", err)
 	}
 
 	log.Printf("GetByID (repo) success: subscription found with id=%v", s.ID)
 	return &s, nil
 }
 
-func (r *subscriptionRepo) GetAll() ([]model.Subscription, error) {
-	log.Println("GetAll (repo): fetching all subscriptions")
+// statusSQL вычисляет статус подписки из таблицы subscriptions на дату,
+// переданную параметром %[1]s, по тем же правилам, что service.Status.
+const statusSQL = `CASE
+		WHEN end_date < %[1]s THEN 'expired'
+		WHEN cancelled_at IS NOT NULL THEN 'cancelled'
+		WHEN (SELECT paused_from FROM subscription_pauses WHERE subscription_id = subscriptions.id AND resumed_on IS NULL) <= %[1]s THEN 'paused'
+		WHEN trial_end_date >= %[1]s THEN 'trial'
+		ELSE 'active'
+	END`
+
+// subscriptionSorts — колонка и тип значения курсора для каждого поля
+// сортировки.
+var subscriptionSorts = map[string]struct{ column, cast string }{
+	model.SortStartDate:   {"start_date", "date"},
+	model.SortPrice:       {"price", "bigint"},
+	model.SortServiceName: {"service_name", "text"},
+}
+
+// cursorValue возвращает значение поля сортировки sort подписки s в виде,
+// который читает соответствующее приведение типа из subscriptionSorts.
+func cursorValue(sort string, s *model.Subscription) string {
+	switch sort {
+	case model.SortPrice:
+		return strconv.FormatInt(s.Price.Amount, 10)
+	case model.SortServiceName:
+		return s.ServiceName
+	}
+	return utils.FormatDate(s.StartDate)
+}
+
+func (r *subscriptionRepo) GetAll(filter model.SubscriptionFilter) (*model.SubscriptionPage, error) {
+	log.Printf("GetAll (repo): fetching subscriptions user_id=%v, service_name=%v, active_at=%v, status=%v, sort=%v, desc=%v, limit=%v", filter.UserID, filter.ServiceName, filter.ActiveAt, filter.Status, filter.Sort, filter.Desc, filter.Limit)
+	sort, ok := subscriptionSorts[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort %q", filter.Sort)
+	}
+
+	var conds []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.UserID != nil {
+		conds = append(conds, "user_id = "+arg(*filter.UserID))
+	}
+	if filter.ServiceName != nil {
+		conds = append(conds, "service_name = "+arg(*filter.ServiceName))
+	}
+	if filter.ActiveAt != nil {
+		conds = append(conds, fmt.Sprintf("start_date <= %s AND (end_date IS NULL OR end_date >= %s)",
+			arg(utils.EndOfMonth(*filter.ActiveAt)), arg(utils.StartOfMonth(*filter.ActiveAt))))
+	}
+	if filter.MinPrice != nil {
+		conds = append(conds, "price >= "+arg(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		conds = append(conds, "price <= "+arg(*filter.MaxPrice))
+	}
+	if filter.Status != "" {
+		conds = append(conds, fmt.Sprintf(statusSQL, arg(utils.StartOfDay(filter.At))+"::date")+" = "+arg(filter.Status))
+	}
+
+	order, cmp := "ASC", ">"
+	if filter.Desc {
+		order, cmp = "DESC", "<"
+	}
+	if filter.After != nil {
+		conds = append(conds, fmt.Sprintf("(%s, id) %s (%s::%s, %s)", sort.column, cmp, arg(filter.After.Value), sort.cast, arg(filter.After.ID)))
+	}
+
+	query := `
+	SELECT ` + subscriptionColumns + `
+	FROM subscriptions`
+	if len(conds) > 0 {
+		query += `
+	WHERE ` + strings.Join(conds, " AND ")
+	}
+	// Лишняя строка показывает, есть ли следующая страница.
+	query += fmt.Sprintf(`
+	ORDER BY %[1]s %[2]s, id %[2]s
+	LIMIT %[3]s`, sort.column, order, arg(filter.Limit+1))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Printf("GetAll (repo) query error: %v", err)
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	page := &model.SubscriptionPage{Items: make([]model.Subscription, 0, filter.Limit)}
+
+	for rows.Next() {
+		var s model.Subscription
+
+		err = scanSubscription(rows, &s)
+		if err != nil {
+			log.Printf("GetAll (repo) scan error: %v", err)
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		page.Items = append(page.Items, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("GetAll (repo) rows error: %v", err)
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	if len(page.Items) > filter.Limit {
+		page.Items = page.Items[:filter.Limit]
+		last := &page.Items[len(page.Items)-1]
+		page.Next = &model.SubscriptionCursor{Sort: filter.Sort, Desc: filter.Desc, Value: cursorValue(filter.Sort, last), ID: last.ID}
+	}
+
+	log.Printf("GetAll (repo) success: found %d subscriptions, has_next=%v", len(page.Items), page.Next != nil)
+	return page, nil
+}
+
+func (r *subscriptionRepo) GetActiveByUser(userID uuid.UUID, at time.Time) ([]model.Subscription, error) {
+	log.Printf("GetActiveByUser (repo): fetching subscriptions for user_id=%v active at %v", userID, at)
 	rows, err := r.db.Query(`
-	SELECT id, service_name, price, user_id, start_date, end_date
+	SELECT `+subscriptionColumns+`
 	FROM subscriptions
-	`)
+	WHERE user_id = $1
+	AND (end_date IS NULL OR end_date >= $2)
+	ORDER BY start_date
+	`, userID, at)
 	if err != nil {
-		log.Printf("GetAll (repo) query error: %v", err)
-		return nil, fmt.Errorf("failed to get subscriptions: %v", err)
+		log.Printf("GetActiveByUser (repo) query error: %v", err)
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
 	}
 	defer rows.Close()
 
@@ -86,122 +407,397 @@ func (r *subscriptionRepo) GetAll() ([]model.Subscription, error) {
 	for rows.Next() {
 		var s model.Subscription
 
-		err = rows.Scan(&s.ID, &s.ServiceName, &s.Price, &s.UserID, &s.StartDate, &s.EndDate)
+		err = scanSubscription(rows, &s)
 		if err != nil {
-			log.Printf("GetAll (repo) scan error: %v", err)
-			return nil, fmt.Errorf("failed to scan subscription: %v", err)
+			log.Printf("GetActiveByUser (repo) scan error: %v", err)
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
 		}
 		subscriptions = append(subscriptions, s)
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Printf("GetAll (repo) rows error: %v", err)
-		return nil, fmt.Errorf("failed to get subscriptions: %v", err)
+		log.Printf("GetActiveByUser (repo) rows error: %v", err)
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
 	}
 
-	log.Printf("GetAll (repo) success: found %d subscriptions", len(subscriptions))
+	log.Printf("GetActiveByUser (repo) success: found %d subscriptions", len(subscriptions))
 	return subscriptions, nil
 }
 
-func (r *subscriptionRepo) Update(subscription *model.Subscription) error {
-	log.Printf("Update (repo): updating subscription for id=%v", subscription.ID)
-	var exists int
+func (r *subscriptionRepo) Update(id uuid.UUID, apply func(subscription *model.Subscription) error) (*model.Subscription, error) {
+	log.Printf("Update (repo): updating subscription for id=%v", id)
+	var subscription model.Subscription
 
 	tx, err := r.db.Begin()
 	if err != nil {
 		log.Printf("Update (repo) transaction error: %v", err)
-		return err
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	err = tx.QueryRow(
+	err = scanSubscription(tx.QueryRow(
 		`
-		SELECT 1
+		SELECT `+subscriptionColumns+`
 		FROM subscriptions
 		WHERE id = $1
 		FOR UPDATE
-		`, subscription.ID).Scan(&exists)
+		`, id), &subscription)
 	if err != nil {
+		tx.Rollback()
 		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("Update (repo) not found: %v", err)
-			tx.Rollback()
-			return fmt.Errorf("subscription not found: %v", err)
+			return nil, sql.ErrNoRows
 		}
 		log.Printf("Update (repo) query error: %v", err)
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	oldPrice := subscription.Price
+	if err := apply(&subscription); err != nil {
+		log.Printf("Update (repo) apply error: %v", err)
 		tx.Rollback()
-		return fmt.Errorf("failed to update subscription: %v", err)
+		return nil, err
 	}
+	subscription.ID = id
 
 	_, err = tx.Exec(
 		`
 		UPDATE subscriptions
-		SET service_name = $2, price = $3, user_id = $4, start_date = $5, end_date = $6
+		SET service_name = $2, price = $3, currency = $4, billing_cycle = $5, billing_interval = $6, billing_anchor_day = $7, user_id = $8, start_date = $9, end_date = $10, trial_end_date = $11, allow_overlap = $12
 		WHERE id = $1
-		`, subscription.ID, subscription.ServiceName, subscription.Price, subscription.UserID, subscription.StartDate, subscription.EndDate)
+		`, subscription.ID, subscription.ServiceName, subscription.Price.Amount, subscription.Price.Currency, subscription.BillingCycle, subscription.BillingInterval, subscription.BillingAnchorDay, subscription.UserID, subscription.StartDate, subscription.EndDate, subscription.TrialEndDate, subscription.AllowOverlap)
 	if err != nil {
 		log.Printf("Update (repo) error: %v", err)
 		tx.Rollback()
-		return fmt.Errorf("failed to update subscription: %v", err)
+		if isExclusionViolation(err) {
+			return nil, ErrOverlap
+		}
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	// Новая цена или валюта действует с сегодняшнего дня (или со
+	// start_date, если подписка ещё не началась): уже прошедшие списания,
+	// в том числе в текущем месяце, остаются по старой цене в старой валюте.
+	if subscription.Price != oldPrice {
+		_, err = tx.Exec(
+			`
+			INSERT INTO subscription_prices (subscription_id, price, currency, effective_from)
+			VALUES ($1, $2, $3, GREATEST(CURRENT_DATE, $4::date))
+			ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price, currency = EXCLUDED.currency
+			`, subscription.ID, subscription.Price.Amount, subscription.Price.Currency, subscription.StartDate)
+		if err != nil {
+			log.Printf("Update (repo) price error: %v", err)
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to update subscription price: %w", err)
+		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		log.Panicf("Update (repo) commit error: %v", err)
-		return fmt.Errorf("failed to commit transaction: %v", err)
+		log.Printf("Update (repo) commit error: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	log.Printf("Update (repo) success: updated subscription with id=%v", subscription.ID)
-	return nil
+	return &subscription, nil
 }
 
 func (r *subscriptionRepo) Delete(id uuid.UUID) error {
 	log.Printf("Delete (repo): deleting subscription for id=%v", id)
-	_, err := r.db.Exec(
+	res, err := r.db.Exec(
 		`
 		DELETE FROM subscriptions
 		WHERE id = $1
 		`, id)
 	if err != nil {
 		log.Printf("Delete (repo) error: %v", err)
-		return fmt.Errorf("failed to delete subscription: %v", err)
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("Delete (repo) rows affected error: %v", err)
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	if n == 0 {
+		log.Printf("Delete (repo) not found: id=%v", id)
+		return sql.ErrNoRows
 	}
 
 	log.Printf("Delete (repo) success: deleted subscription with id=%v", id)
 	return nil
 }
 
-func (r *subscriptionRepo) GetTotalAmount(userID uuid.UUID, serviceName *string, from, to time.Time) (int, error) {
-	log.Printf("GetTotalAmount (repo): getting total amount for user_id=%v, service_name=%v, from=%v, to=%v", userID, serviceName, from, to)
-	var totalAmount sql.NullInt64
+func (r *subscriptionRepo) GetMonthlyCharges(filter model.AmountFilter) ([]model.MonthlyCharge, error) {
+	log.Printf("GetMonthlyCharges (repo): getting monthly charges for user_id=%v, service_name=%v, from=%v, to=%v", filter.UserID, filter.ServiceName, filter.From, filter.To)
 
-	query := `SELECT SUM(price)
-	FROM subscriptions
-	WHERE user_id = $1
-	AND start_date BETWEEN $2 AND $3`
+	query := `SELECT date_trunc('month', c.charge_date)::date, s.service_name, p.currency, t.trial, SUM(p.price)::bigint` + chargesFrom + `
+	AND s.user_id = $3`
+
+	args := []interface{}{utils.StartOfMonth(filter.From), utils.EndOfMonth(filter.To), filter.UserID}
+
+	if filter.ServiceName != nil {
+		args = append(args, *filter.ServiceName)
+		query += fmt.Sprintf(" AND s.service_name = $%d", len(args))
+	}
+
+	if filter.ExcludeID != nil {
+		args = append(args, *filter.ExcludeID)
+		query += fmt.Sprintf(" AND s.id <> $%d", len(args))
+	}
+
+	query += `
+	GROUP BY 1, s.service_name, p.currency, t.trial
+	ORDER BY 1, s.service_name, p.currency, t.trial`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Printf("GetMonthlyCharges (repo) query error: %v", err)
+		return nil, fmt.Errorf("failed to get monthly charges: %w", err)
+	}
+	defer rows.Close()
+
+	var charges []model.MonthlyCharge
+
+	for rows.Next() {
+		var c model.MonthlyCharge
+
+		err = rows.Scan(&c.Month, &c.ServiceName, &c.Currency, &c.Trial, &c.Amount)
+		if err != nil {
+			log.Printf("GetMonthlyCharges (repo) scan error: %v", err)
+			return nil, fmt.Errorf("failed to scan monthly charge: %w", err)
+		}
+		charges = append(charges, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("GetMonthlyCharges (repo) rows error: %v", err)
+		return nil, fmt.Errorf("failed to get monthly charges: %w", err)
+	}
+
+	log.Printf("GetMonthlyCharges (repo) success: found %d charge groups", len(charges))
+	return charges, nil
+}
+
+func (r *subscriptionRepo) AddPriceChange(change *model.PriceChange) error {
+	log.Printf("AddPriceChange (repo): scheduling price=%v for subscription_id=%v from %v", change.Price, change.SubscriptionID, change.EffectiveFrom)
 
-	args := []interface{}{userID, from, to}
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Printf("AddPriceChange (repo) transaction error: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	err = tx.QueryRow(
+		`
+		INSERT INTO subscription_prices (subscription_id, price, currency, effective_from)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price, currency = EXCLUDED.currency
+		RETURNING id, created_at
+		`, change.SubscriptionID, change.Price.Amount, change.Price.Currency, change.EffectiveFrom).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		log.Printf("AddPriceChange (repo) error: %v", err)
+		tx.Rollback()
+		return fmt.Errorf("failed to add price change: %w", err)
+	}
+
+	// subscriptions.price и currency хранят цену, действующую на сегодня.
+	_, err = tx.Exec(
+		`
+		UPDATE subscriptions s
+		SET price = cur.price, currency = cur.currency
+		FROM (
+			SELECT sp.price, sp.currency
+			FROM subscription_prices sp
+			WHERE sp.subscription_id = $1 AND sp.effective_from <= CURRENT_DATE
+			ORDER BY sp.effective_from DESC
+			LIMIT 1
+		) cur
+		WHERE s.id = $1
+		`, change.SubscriptionID)
+	if err != nil {
+		log.Printf("AddPriceChange (repo) current price error: %v", err)
+		tx.Rollback()
+		return fmt.Errorf("failed to update current price: %w", err)
+	}
 
-	if serviceName != nil {
-		query += " AND service_name = $4"
-		args = append(args, *serviceName)
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("AddPriceChange (repo) commit error: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	err := r.db.QueryRow(query, args...).Scan(&totalAmount)
+	log.Printf("AddPriceChange (repo) success: price change id=%v", change.ID)
+	return nil
+}
 
+func (r *subscriptionRepo) GetPriceHistory(subscriptionID uuid.UUID) ([]model.PriceChange, error) {
+	log.Printf("GetPriceHistory (repo): fetching prices for subscription_id=%v", subscriptionID)
+	rows, err := r.db.Query(`
+	SELECT id, subscription_id, price, currency, effective_from, created_at
+	FROM subscription_prices
+	WHERE subscription_id = $1
+	ORDER BY effective_from
+	`, subscriptionID)
+	if err != nil {
+		log.Printf("GetPriceHistory (repo) query error: %v", err)
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+	defer rows.Close()
+
+	var prices []model.PriceChange
+
+	for rows.Next() {
+		var p model.PriceChange
+
+		err = rows.Scan(&p.ID, &p.SubscriptionID, &p.Price.Amount, &p.Price.Currency, &p.EffectiveFrom, &p.CreatedAt)
+		if err != nil {
+			log.Printf("GetPriceHistory (repo) scan error: %v", err)
+			return nil, fmt.Errorf("failed to scan price change: %w", err)
+		}
+		prices = append(prices, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("GetPriceHistory (repo) rows error: %v", err)
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	log.Printf("GetPriceHistory (repo) success: found %d prices", len(prices))
+	return prices, nil
+}
+
+func (r *subscriptionRepo) GetOverlapping(subscription *model.Subscription) (*model.Subscription, error) {
+	log.Printf("GetOverlapping (repo): checking overlaps for user_id=%v, service_name=%v, start_date=%v, end_date=%v", subscription.UserID, subscription.ServiceName, subscription.StartDate, subscription.EndDate)
+	var s model.Subscription
+
+	err := scanSubscription(r.db.QueryRow(
+		`
+		SELECT `+subscriptionColumns+`
+		FROM subscriptions
+		WHERE user_id = $1
+		AND service_name = $2
+		AND id <> $3
+		AND NOT allow_overlap
+		AND daterange(start_date, end_date, '[]') && daterange($4::date, $5::date, '[]')
+		ORDER BY start_date
+		LIMIT 1
+		`, subscription.UserID, subscription.ServiceName, subscription.ID, subscription.StartDate, subscription.EndDate), &s)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			log.Printf("GetTotalAmount (repo) not found: %v", err)
-			return 0, sql.ErrNoRows
+			log.Println("GetOverlapping (repo) success: no overlapping subscriptions")
+			return nil, sql.ErrNoRows
 		}
+		log.Printf("GetOverlapping (repo) error: %v", err)
+		return nil, fmt.Errorf("failed to check overlapping subscriptions: %w", err)
+	}
+
+	log.Printf("GetOverlapping (repo) success: subscription %v overlaps", s.ID)
+	return &s, nil
+}
 
-		log.Printf("GetTotalAmount (repo) error: %v", err)
-		return 0, fmt.Errorf("failed to get total amount: %v", err)
+func (r *subscriptionRepo) Cancel(subscription *model.Subscription) error {
+	log.Printf("Cancel (repo): cancelling subscription id=%v with end_date=%v", subscription.ID, subscription.EndDate)
+	res, err := r.db.Exec(
+		`
+		UPDATE subscriptions
+		SET end_date = $2, cancelled_at = $3, cancel_reason = $4
+		WHERE id = $1
+		`, subscription.ID, subscription.EndDate, subscription.CancelledAt, subscription.CancelReason)
+	if err != nil {
+		log.Printf("Cancel (repo) error: %v", err)
+		return fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("Cancel (repo) rows affected error: %v", err)
+		return fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+	if n == 0 {
+		log.Printf("Cancel (repo) not found: id=%v", subscription.ID)
+		return sql.ErrNoRows
 	}
 
-	if !totalAmount.Valid {
-		log.Println("GetTotalAmount (repo): result is NULL, returning 0")
-		return 0, nil
+	log.Printf("Cancel (repo) success: cancelled subscription with id=%v", subscription.ID)
+	return nil
+}
+
+func (r *subscriptionRepo) AddPause(pause *model.Pause) error {
+	log.Printf("AddPause (repo): pausing subscription_id=%v from %v", pause.SubscriptionID, pause.PausedFrom)
+	err := r.db.QueryRow(
+		`
+		INSERT INTO subscription_pauses (subscription_id, paused_from)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+		`, pause.SubscriptionID, pause.PausedFrom).Scan(&pause.ID, &pause.CreatedAt)
+	if err != nil {
+		log.Printf("AddPause (repo) error: %v", err)
+		if isUniqueViolation(err) {
+			return ErrPauseExists
+		}
+		return fmt.Errorf("failed to add pause: %w", err)
+	}
+
+	log.Printf("AddPause (repo) success: pause id=%v", pause.ID)
+	return nil
+}
+
+func (r *subscriptionRepo) Resume(subscriptionID uuid.UUID, on time.Time) (*model.Pause, error) {
+	log.Printf("Resume (repo): resuming subscription_id=%v on %v", subscriptionID, on)
+	var p model.Pause
+
+	err := r.db.QueryRow(
+		`
+		UPDATE subscription_pauses
+		SET resumed_on = GREATEST($2::date, paused_from)
+		WHERE subscription_id = $1 AND resumed_on IS NULL
+		RETURNING id, subscription_id, paused_from, resumed_on, created_at
+		`, subscriptionID, on).Scan(&p.ID, &p.SubscriptionID, &p.PausedFrom, &p.ResumedOn, &p.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Resume (repo) not found: %v", err)
+			return nil, sql.ErrNoRows
+		}
+		log.Printf("Resume (repo) error: %v", err)
+		return nil, fmt.Errorf("failed to resume subscription: %w", err)
+	}
+
+	log.Printf("Resume (repo) success: pause id=%v closed", p.ID)
+	return &p, nil
+}
+
+func (r *subscriptionRepo) GetPauses(subscriptionID uuid.UUID) ([]model.Pause, error) {
+	log.Printf("GetPauses (repo): fetching pauses for subscription_id=%v", subscriptionID)
+	rows, err := r.db.Query(`
+	SELECT id, subscription_id, paused_from, resumed_on, created_at
+	FROM subscription_pauses
+	WHERE subscription_id = $1
+	ORDER BY paused_from
+	`, subscriptionID)
+	if err != nil {
+		log.Printf("GetPauses (repo) query error: %v", err)
+		return nil, fmt.Errorf("failed to get pauses: %w", err)
+	}
+	defer rows.Close()
+
+	var pauses []model.Pause
+
+	for rows.Next() {
+		var p model.Pause
+
+		err = rows.Scan(&p.ID, &p.SubscriptionID, &p.PausedFrom, &p.ResumedOn, &p.CreatedAt)
+		if err != nil {
+			log.Printf("GetPauses (repo) scan error: %v", err)
+			return nil, fmt.Errorf("failed to scan pause: %w", err)
+		}
+		pauses = append(pauses, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("GetPauses (repo) rows error: %v", err)
+		return nil, fmt.Errorf("failed to get pauses: %w", err)
 	}
 
-	log.Printf("GetTotalAmount (repo) success: total amount = %d", totalAmount.Int64)
-	return int(totalAmount.Int64), nil
+	log.Printf("GetPauses (repo) success: found %d pauses", len(pauses))
+	return pauses, nil
 }
@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"database/sql"
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/pgk/money"
+	"go-subscriptions-service/pgk/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+// testDB подключается к PostgreSQL из TEST_DATABASE_URL и накатывает
+// миграции во временную схему, которая удаляется после теста. Без
+// TEST_DATABASE_URL тест пропускается.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// search_path задаётся для соединения, поэтому соединение одно.
+	db.SetMaxOpenConns(1)
+
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := db.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA " + schema + " CASCADE")
+		db.Close()
+	})
+	if _, err := db.Exec("SET search_path TO " + schema + ", public"); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := filepath.Glob("../../migrations/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(migrations)
+	for _, m := range migrations {
+		query, err := os.ReadFile(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(query)); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(m), err)
+		}
+	}
+
+	return db
+}
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := utils.ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestGetMonthlyCharges(t *testing.T) {
+	r := NewSubscriptionRepo(testDB(t))
+
+	tests := []struct {
+		name       string
+		start, end string
+		from, to   string
+		want       map[string]int64
+	}{
+		{
+			name:  "subscription started before from",
+			start: "2024-01-15", end: "2024-12-15",
+			from: "2024-03-01", to: "2024-05-01",
+			want: map[string]int64{"2024-03-01": 1000, "2024-04-01": 1000, "2024-05-01": 1000},
+		},
+		{
+			name:  "end_date NULL",
+			start: "2024-11-20",
+			from:  "2024-11-01", to: "2025-02-01",
+			want: map[string]int64{"2024-11-01": 1000, "2024-12-01": 1000, "2025-01-01": 1000, "2025-02-01": 1000},
+		},
+		{
+			name:  "charge on end_date is counted",
+			start: "2024-01-10", end: "2024-04-10",
+			from: "2024-01-01", to: "2024-06-01",
+			want: map[string]int64{"2024-01-01": 1000, "2024-02-01": 1000, "2024-03-01": 1000, "2024-04-01": 1000},
+		},
+		{
+			name:  "end_date before the charge day",
+			start: "2024-01-10", end: "2024-04-05",
+			from: "2024-01-01", to: "2024-06-01",
+			want: map[string]int64{"2024-01-01": 1000, "2024-02-01": 1000, "2024-03-01": 1000},
+		},
+		{
+			name:  "single-month range",
+			start: "2024-01-31",
+			from:  "2024-02-01", to: "2024-02-01",
+			want: map[string]int64{"2024-02-01": 1000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &model.Subscription{
+				ServiceName:  "netflix",
+				Price:        money.New(1000, "RUB"),
+				BillingCycle: model.BillingMonthly,
+				UserID:       uuid.New(),
+				StartDate:    date(t, tt.start),
+			}
+			sub.BillingAnchorDay = sub.StartDate.Day()
+			if tt.end != "" {
+				end := date(t, tt.end)
+				sub.EndDate = &end
+			}
+			if err := r.Create(sub); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			charges, err := r.GetMonthlyCharges(model.AmountFilter{
+				UserID: sub.UserID,
+				From:   date(t, tt.from),
+				To:     date(t, tt.to),
+			})
+			if err != nil {
+				t.Fatalf("GetMonthlyCharges() error = %v", err)
+			}
+
+			got := make(map[string]int64, len(charges))
+			for _, c := range charges {
+				got[utils.FormatDate(c.Month)] += c.Amount
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d months %v, want %d %v", len(got), got, len(tt.want), tt.want)
+			}
+			for month, want := range tt.want {
+				if got[month] != want {
+					t.Errorf("month %s: got %d, want %d", month, got[month], want)
+				}
+			}
+		})
+	}
+}
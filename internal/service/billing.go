@@ -0,0 +1,136 @@
+package service
+
+import (
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/pgk/money"
+	"go-subscriptions-service/pgk/utils"
+	"time"
+)
+
+// cycleMonths возвращает длину периода оплаты в месяцах; для weekly — 0.
+func cycleMonths(sub *model.Subscription) int {
+	switch sub.BillingCycle {
+	case model.BillingMonthly:
+		return 1
+	case model.BillingQuarterly:
+		return 3
+	case model.BillingYearly:
+		return 12
+	case model.BillingCustom:
+		return sub.BillingInterval
+	}
+	return 0
+}
+
+// NormalizedMonthlyCost приводит цену подписки к стоимости одного месяца,
+// чтобы годовые и еженедельные тарифы можно было сравнивать с помесячными.
+func NormalizedMonthlyCost(sub *model.Subscription) money.Money {
+	if sub.BillingCycle == model.BillingWeekly {
+		return sub.Price.MulRat(52, 12)
+	}
+
+	months := cycleMonths(sub)
+	if months <= 0 {
+		return sub.Price
+	}
+
+	return sub.Price.MulRat(1, int64(months))
+}
+
+// InTrial сообщает, приходится ли дата at на пробный период подписки.
+func InTrial(sub *model.Subscription, at time.Time) bool {
+	return sub.TrialEndDate != nil && !utils.StartOfDay(at).After(*sub.TrialEndDate)
+}
+
+// Paused сообщает, действует ли на дату at открытая пауза подписки.
+func Paused(sub *model.Subscription, at time.Time) bool {
+	return sub.PausedFrom != nil && !sub.PausedFrom.After(utils.StartOfDay(at))
+}
+
+// NextBillingDate возвращает первую дату списания не раньше from. Даты
+// считаются так же, как в отчётах repo: от start_date с шагом цикла оплаты,
+// до end_date включительно и до начала открытой паузы. ok = false, если
+// списаний больше не будет (или до возобновления).
+func NextBillingDate(sub *model.Subscription, from time.Time) (next time.Time, ok bool) {
+	from = utils.StartOfDay(from)
+	start := utils.StartOfDay(sub.StartDate)
+
+	switch {
+	case !from.After(start):
+		next = start
+	case sub.BillingCycle == model.BillingWeekly:
+		weeks := (int(from.Sub(start).Hours()/24) + 6) / 7
+		next = start.AddDate(0, 0, weeks*7)
+	default:
+		months := cycleMonths(sub)
+		if months <= 0 {
+			return time.Time{}, false
+		}
+		k := utils.MonthsBetween(start, from) / months
+		next = monthlyChargeDate(sub, k*months)
+		for next.Before(from) {
+			k++
+			next = monthlyChargeDate(sub, k*months)
+		}
+	}
+
+	if sub.EndDate != nil && next.After(*sub.EndDate) {
+		return time.Time{}, false
+	}
+
+	if sub.PausedFrom != nil && !next.Before(*sub.PausedFrom) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+// monthlyChargeDate возвращает дату списания через offset месяцев после
+// месяца start_date: день billing_anchor_day, прижатый к концу месяца.
+func monthlyChargeDate(sub *model.Subscription, offset int) time.Time {
+	day := sub.BillingAnchorDay
+	if day == 0 {
+		day = sub.StartDate.Day()
+	}
+	return utils.AnchorDate(utils.AddMonths(utils.StartOfMonth(sub.StartDate), offset), day)
+}
+
+// PeriodEnd возвращает последний день периода оплаты, в который попадает
+// дата at (at не раньше start_date): день перед следующим списанием.
+// end_date и пауза подписки не учитываются.
+func PeriodEnd(sub *model.Subscription, at time.Time) (time.Time, bool) {
+	open := *sub
+	open.EndDate = nil
+	open.PausedFrom = nil
+
+	next, ok := NextBillingDate(&open, utils.StartOfDay(at).AddDate(0, 0, 1))
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return next.AddDate(0, 0, -1), true
+}
+
+// chargeDates возвращает все даты списаний подписки в [from, to].
+func chargeDates(sub *model.Subscription, from, to time.Time) []time.Time {
+	var dates []time.Time
+	next, ok := NextBillingDate(sub, from)
+	for ok && !next.After(to) {
+		dates = append(dates, next)
+		next, ok = NextBillingDate(sub, next.AddDate(0, 0, 1))
+	}
+	return dates
+}
+
+// priceAt возвращает цену, действующую на дату at, по истории цен,
+// отсортированной по EffectiveFrom; без подходящей записи — sub.Price.
+func priceAt(sub *model.Subscription, prices []model.PriceChange, at time.Time) money.Money {
+	price := sub.Price
+	for _, p := range prices {
+		if p.EffectiveFrom.After(at) {
+			break
+		}
+		price = p.Price
+	}
+	return price
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/pgk/utils"
+	"testing"
+)
+
+func TestNextBillingDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cycle    string
+		interval int
+		start    string
+		end      string
+		paused   string
+		from     string
+		want     string // "" — списаний больше нет
+	}{
+		{name: "from before start", cycle: model.BillingMonthly, start: "2024-03-10", from: "2024-01-01", want: "2024-03-10"},
+		{name: "from on a charge date", cycle: model.BillingMonthly, start: "2024-01-10", from: "2024-04-10", want: "2024-04-10"},
+		{name: "from after a charge date", cycle: model.BillingMonthly, start: "2024-01-10", from: "2024-04-11", want: "2024-05-10"},
+		{name: "anchor day clamped to february", cycle: model.BillingMonthly, start: "2024-01-31", from: "2024-02-01", want: "2024-02-29"},
+		{name: "anchor day kept after february", cycle: model.BillingMonthly, start: "2024-01-31", from: "2024-03-01", want: "2024-03-31"},
+		{name: "weekly", cycle: model.BillingWeekly, start: "2024-01-01", from: "2024-01-03", want: "2024-01-08"},
+		{name: "quarterly", cycle: model.BillingQuarterly, start: "2024-01-15", from: "2024-02-01", want: "2024-04-15"},
+		{name: "yearly", cycle: model.BillingYearly, start: "2023-05-10", from: "2024-01-01", want: "2024-05-10"},
+		{name: "custom interval", cycle: model.BillingCustom, interval: 2, start: "2024-01-05", from: "2024-02-01", want: "2024-03-05"},
+		{name: "charge on end_date", cycle: model.BillingMonthly, start: "2024-01-10", end: "2024-04-10", from: "2024-04-01", want: "2024-04-10"},
+		{name: "next charge after end_date", cycle: model.BillingMonthly, start: "2024-01-10", end: "2024-04-09", from: "2024-03-11", want: ""},
+		{name: "next charge during a pause", cycle: model.BillingMonthly, start: "2024-01-10", paused: "2024-03-01", from: "2024-03-01", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := testSubscription(t, tt.cycle, tt.start)
+			sub.BillingInterval = tt.interval
+			if tt.end != "" {
+				end := date(t, tt.end)
+				sub.EndDate = &end
+			}
+			if tt.paused != "" {
+				paused := date(t, tt.paused)
+				sub.PausedFrom = &paused
+			}
+
+			next, ok := NextBillingDate(sub, date(t, tt.from))
+			if tt.want == "" {
+				if ok {
+					t.Errorf("NextBillingDate() = %s, want no charge", utils.FormatDate(next))
+				}
+				return
+			}
+			if !ok || utils.FormatDate(next) != tt.want {
+				t.Errorf("NextBillingDate() = %s, %v, want %s", utils.FormatDate(next), ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodEnd(t *testing.T) {
+	tests := []struct {
+		name  string
+		cycle string
+		start string
+		end   string
+		at    string
+		want  string
+	}{
+		{name: "middle of a period", cycle: model.BillingMonthly, start: "2024-01-10", at: "2024-03-15", want: "2024-04-09"},
+		{name: "on a charge date", cycle: model.BillingMonthly, start: "2024-01-10", at: "2024-03-10", want: "2024-04-09"},
+		{name: "last day of a period", cycle: model.BillingMonthly, start: "2024-01-10", at: "2024-03-09", want: "2024-03-09"},
+		{name: "anchor day clamped to february", cycle: model.BillingMonthly, start: "2024-01-31", at: "2024-02-15", want: "2024-02-28"},
+		{name: "yearly", cycle: model.BillingYearly, start: "2023-05-10", at: "2024-01-01", want: "2024-05-09"},
+		{name: "end_date is ignored", cycle: model.BillingMonthly, start: "2024-01-10", end: "2024-03-20", at: "2024-03-15", want: "2024-04-09"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := testSubscription(t, tt.cycle, tt.start)
+			if tt.end != "" {
+				end := date(t, tt.end)
+				sub.EndDate = &end
+			}
+
+			got, ok := PeriodEnd(sub, date(t, tt.at))
+			if !ok || utils.FormatDate(got) != tt.want {
+				t.Errorf("PeriodEnd() = %s, %v, want %s", utils.FormatDate(got), ok, tt.want)
+			}
+		})
+	}
+}
+
+func testSubscription(t *testing.T, cycle, start string) *model.Subscription {
+	t.Helper()
+	sub := &model.Subscription{BillingCycle: cycle, StartDate: date(t, start)}
+	sub.BillingAnchorDay = sub.StartDate.Day()
+	return sub
+}
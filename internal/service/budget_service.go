@@ -0,0 +1,137 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/internal/repo"
+	"go-subscriptions-service/pgk/validator"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BudgetService interface {
+	Create(budget *model.Budget) error
+	GetByUserID(userID uuid.UUID) (*model.Budget, error)
+	Update(budget *model.Budget) error
+	Delete(userID uuid.UUID) error
+	// GetStatus сравнивает расходы пользователя за каждый месяц между from
+	// и to с его бюджетом; суммы считаются в валюте бюджета.
+	GetStatus(userID uuid.UUID, from, to time.Time) ([]model.BudgetMonth, error)
+}
+
+type budgetService struct {
+	repo          repo.BudgetRepository
+	subscriptions SubscriptionService
+}
+
+func NewBudgetService(r repo.BudgetRepository, subscriptions SubscriptionService) BudgetService {
+	return &budgetService{repo: r, subscriptions: subscriptions}
+}
+
+func (s *budgetService) Create(budget *model.Budget) error {
+	log.Printf("Create (budget service) called: user_id=%v, amount=%v, currency=%v, enforce=%v", budget.UserID, budget.Amount, budget.Amount.Currency, budget.Enforce)
+	if err := validator.ValidateBudget(budget); err != nil {
+		log.Println("Create (budget service) error: invalid budget ", err)
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	_, err := s.repo.GetByUserID(budget.UserID)
+	if err == nil {
+		log.Println("Create (budget service) error: budget already exists")
+		return ErrBudgetExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Println("Create (budget service) error: failed to get budget ", err)
+		return err
+	}
+
+	// Проверка выше не защищает от конкурентного создания: второй запрос
+	// упрётся в первичный ключ budgets.
+	if err := s.repo.Create(budget); err != nil {
+		log.Println("Create (budget service) error: failed to create budget ", err)
+		if errors.Is(err, repo.ErrBudgetExists) {
+			return ErrBudgetExists
+		}
+		return err
+	}
+
+	log.Println("Create (budget service) success: budget created")
+	return nil
+}
+
+func (s *budgetService) GetByUserID(userID uuid.UUID) (*model.Budget, error) {
+	log.Printf("GetByUserID (budget service) called: user_id=%v", userID)
+	budget, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		log.Println("GetByUserID (budget service) error: failed to get budget ", err)
+		return nil, notFound(err, "budget for user", userID)
+	}
+
+	log.Println("GetByUserID (budget service) success: budget found ", budget)
+	return budget, nil
+}
+
+func (s *budgetService) Update(budget *model.Budget) error {
+	log.Printf("Update (budget service) called: user_id=%v", budget.UserID)
+	if err := validator.ValidateBudget(budget); err != nil {
+		log.Println("Update (budget service) error: invalid budget ", err)
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	if err := s.repo.Update(budget); err != nil {
+		log.Println("Update (budget service) error: failed to update budget ", err)
+		return notFound(err, "budget for user", budget.UserID)
+	}
+
+	log.Println("Update (budget service) success: budget updated")
+	return nil
+}
+
+func (s *budgetService) Delete(userID uuid.UUID) error {
+	log.Printf("Delete (budget service) called: user_id=%v", userID)
+	if err := s.repo.Delete(userID); err != nil {
+		log.Println("Delete (budget service) error: failed to delete budget ", err)
+		return notFound(err, "budget for user", userID)
+	}
+
+	log.Println("Delete (budget service) success: budget deleted")
+	return nil
+}
+
+func (s *budgetService) GetStatus(userID uuid.UUID, from, to time.Time) ([]model.BudgetMonth, error) {
+	log.Printf("GetStatus (budget service) called: user_id=%v, from=%v, to=%v", userID, from, to)
+	budget, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		log.Println("GetStatus (budget service) error: failed to get budget ", err)
+		return nil, notFound(err, "budget for user", userID)
+	}
+
+	amounts, err := s.subscriptions.GetMonthlyAmounts(model.AmountFilter{
+		UserID:   userID,
+		From:     from,
+		To:       to,
+		Currency: budget.Amount.Currency,
+	}, false)
+	if err != nil {
+		log.Println("GetStatus (budget service) error: failed to get monthly amounts ", err)
+		return nil, err
+	}
+
+	months := make([]model.BudgetMonth, 0, len(amounts))
+	for _, a := range amounts {
+		months = append(months, model.BudgetMonth{
+			Month:      a.Month,
+			Spent:      a.Amount,
+			Budget:     budget.Amount.Amount,
+			Currency:   budget.Amount.Currency,
+			OverBudget: a.Amount > budget.Amount.Amount,
+		})
+	}
+
+	log.Printf("GetStatus (budget service) success: %d months", len(months))
+	return months, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Базовые категории ошибок сервиса. Обработчики сопоставляют с ними
+// HTTP-статусы через errors.Is, не разбирая конкретные ошибки.
+var (
+	// ErrValidation оборачивает ошибки проверки входных данных на уровне
+	// сервиса, чтобы обработчики могли отвечать на них 400.
+	ErrValidation = errors.New("validation failed")
+	// ErrNotFound — запрошенная сущность не существует; см. NotFoundError.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict — запрос противоречит текущему состоянию данных.
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed — не выполнено предусловие запроса: сущность
+	// не в том состоянии, в котором клиент ожидал её изменить.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrRateUnavailable — для пересчёта суммы нет курса валюты на нужный
+	// месяц; обёрнутая ошибка называет валюту и дату.
+	ErrRateUnavailable = errors.New("exchange rate unavailable")
+)
+
+// Конфликты; errors.Is(err, ErrConflict) для каждого из них истинно.
+var (
+	// ErrBudgetExceeded — изменение подписки выводит будущий месяц за
+	// бюджет пользователя с включённым enforce.
+	ErrBudgetExceeded error = conflictError("budget exceeded")
+	// ErrBudgetExists — у пользователя уже есть бюджет.
+	ErrBudgetExists error = conflictError("budget already exists")
+	// ErrOverlap — у пользователя уже есть подписка на этот сервис на
+	// пересекающиеся даты.
+	ErrOverlap error = conflictError("subscription overlaps an existing subscription")
+	// ErrInvalidTransition — действие недопустимо в текущем статусе подписки.
+	ErrInvalidTransition error = conflictError("invalid status transition")
+)
+
+// conflictError — конкретный конфликт со своим текстом, относящийся к
+// категории ErrConflict.
+type conflictError string
+
+func (e conflictError) Error() string {
+	return string(e)
+}
+
+func (e conflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// NotFoundError сообщает, какая сущность не найдена; errors.Is(err,
+// ErrNotFound) для неё истинно.
+type NotFoundError struct {
+	Entity string
+	ID     any
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %v not found", e.Entity, e.ID)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// notFound заменяет sql.ErrNoRows из репозитория на NotFoundError для
+// сущности entity с идентификатором id. Остальные ошибки возвращаются без
+// изменений.
+func notFound(err error, entity string, id any) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &NotFoundError{Entity: entity, ID: id}
+	}
+	return err
+}
+
+// OverlapError сообщает, с какой подпиской пересекается новая или
+// изменённая; errors.Is(err, ErrOverlap) для неё истинно.
+type OverlapError struct {
+	ConflictingID uuid.UUID
+}
+
+func (e *OverlapError) Error() string {
+	return fmt.Sprintf("%v: conflicting subscription id=%v", ErrOverlap, e.ConflictingID)
+}
+
+func (e *OverlapError) Unwrap() error {
+	return ErrOverlap
+}
+
+// TransitionError сообщает, какое действие и в каком статусе отклонено;
+// errors.Is(err, ErrInvalidTransition) для неё истинно.
+type TransitionError struct {
+	Action string
+	Status string
+	Reason string
+}
+
+func (e *TransitionError) Error() string {
+	msg := fmt.Sprintf("%v: cannot %s a subscription in status %s", ErrInvalidTransition, e.Action, e.Status)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
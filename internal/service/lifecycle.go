@@ -0,0 +1,75 @@
+package service
+
+import (
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/pgk/utils"
+	"time"
+)
+
+// Действия, переводящие подписку между статусами.
+const (
+	actionPause  = "pause"
+	actionResume = "resume"
+	actionCancel = "cancel"
+	// actionEndDate — смена end_date через PUT/PATCH.
+	actionEndDate = "change the end date of"
+)
+
+// transitions — статусы, из которых допустимо каждое действие.
+var transitions = map[string][]string{
+	actionPause:  {model.StatusTrial, model.StatusActive},
+	actionResume: {model.StatusPaused},
+	actionCancel: {model.StatusTrial, model.StatusActive, model.StatusPaused},
+	// Отменённую или истёкшую подписку нельзя продлить или укоротить.
+	actionEndDate: {model.StatusTrial, model.StatusActive, model.StatusPaused},
+}
+
+// Status вычисляет статус подписки на дату at: expired после end_date,
+// cancelled после отмены до end_date, paused во время открытой паузы,
+// trial в пробный период, иначе active.
+func Status(sub *model.Subscription, at time.Time) string {
+	at = utils.StartOfDay(at)
+	switch {
+	case sub.EndDate != nil && sub.EndDate.Before(at):
+		return model.StatusExpired
+	case sub.CancelledAt != nil:
+		return model.StatusCancelled
+	case Paused(sub, at):
+		return model.StatusPaused
+	case InTrial(sub, at):
+		return model.StatusTrial
+	}
+	return model.StatusActive
+}
+
+// checkTransition возвращает TransitionError, если action недопустимо в
+// статусе подписки на дату at.
+func checkTransition(sub *model.Subscription, action string, at time.Time) error {
+	status := Status(sub, at)
+	for _, allowed := range transitions[action] {
+		if status == allowed {
+			return nil
+		}
+	}
+	return &TransitionError{Action: action, Status: status}
+}
+
+// sameDate сообщает, совпадают ли две необязательные даты.
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// withComputed заполняет вычисляемые поля подписок — статус, нормированную
+// месячную стоимость, пробный период и паузу — на текущий момент.
+func withComputed(subs ...*model.Subscription) {
+	now := time.Now()
+	for _, sub := range subs {
+		sub.Status = Status(sub, now)
+		sub.NormalizedMonthlyCost = NormalizedMonthlyCost(sub)
+		sub.InTrial = InTrial(sub, now)
+		sub.Paused = Paused(sub, now)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/internal/repo"
+	"go-subscriptions-service/pgk/currency"
+	"go-subscriptions-service/pgk/money"
+	"log"
+	"time"
+)
+
+type ReportService interface {
+	// GetRevenueReport возвращает по каждому service_name всех пользователей
+	// помесячные число подписчиков, активных подписок и выручку в currency.
+	GetRevenueReport(from, to time.Time, cur string) ([]model.ServiceRevenue, error)
+}
+
+type reportService struct {
+	repo  repo.ReportRepository
+	rates *currency.Rates
+}
+
+func NewReportService(r repo.ReportRepository, rates *currency.Rates) ReportService {
+	return &reportService{repo: r, rates: rates}
+}
+
+func (s *reportService) GetRevenueReport(from, to time.Time, cur string) ([]model.ServiceRevenue, error) {
+	log.Printf("GetRevenueReport (report service) called: from=%v, to=%v, currency=%v", from, to, cur)
+	if err := validatePeriod(from, to); err != nil {
+		log.Println("GetRevenueReport (report service) error: ", err)
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	if !currency.IsSupported(cur) {
+		log.Println("GetRevenueReport (report service) error: unsupported currency ", cur)
+		return nil, fmt.Errorf("%w: unsupported currency %q", ErrValidation, cur)
+	}
+
+	rows, err := s.repo.GetServiceRevenue(from, to)
+	if err != nil {
+		log.Println("GetRevenueReport (report service) error: failed to get service revenue ", err)
+		return nil, err
+	}
+
+	var report []model.ServiceRevenue
+
+	for _, row := range rows {
+		if n := len(report); n == 0 || report[n-1].ServiceName != row.ServiceName {
+			report = append(report, model.ServiceRevenue{ServiceName: row.ServiceName})
+		}
+		service := &report[len(report)-1]
+
+		if n := len(service.Months); n == 0 || !service.Months[n-1].Month.Equal(row.Month) {
+			service.Months = append(service.Months, model.RevenueMonth{
+				Month:               row.Month,
+				Subscribers:         row.Subscribers,
+				ActiveSubscriptions: row.ActiveSubscriptions,
+			})
+		}
+		month := &service.Months[len(service.Months)-1]
+
+		if row.Currency == "" {
+			continue
+		}
+
+		revenue, err := s.rates.Convert(money.New(row.Revenue, row.Currency), cur, row.Month)
+		if err != nil {
+			log.Println("GetRevenueReport (report service) error: failed to convert revenue ", err)
+			return nil, fmt.Errorf("%w: %w", ErrRateUnavailable, err)
+		}
+		month.Revenue += revenue.Amount
+		service.TotalRevenue += revenue.Amount
+	}
+
+	log.Printf("GetRevenueReport (report service) success: %d services", len(report))
+	return report, nil
+}
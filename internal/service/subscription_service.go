@@ -3,10 +3,16 @@ package service
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"go-subscriptions-service/internal/model"
 	"go-subscriptions-service/internal/repo"
+	"go-subscriptions-service/pgk/currency"
+	"go-subscriptions-service/pgk/money"
+	"go-subscriptions-service/pgk/utils"
 	"go-subscriptions-service/pgk/validator"
 	"log"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,99 +20,903 @@ import (
 
 type SubscriptionService interface {
 	Create(subscription *model.Subscription) error
+	// CreateBatch проверяет подписки пакета так же, как Create, с учётом
+	// пересечений и бюджета внутри пакета, и сохраняет их одним запросом.
+	// nil в subs — элемент, отклонённый ещё при разборе запроса. Возвращает
+	// ошибку для каждой отклонённой подписки (nil — подписка создана). При
+	// atomic любая отклонённая подписка отменяет создание всего пакета.
+	CreateBatch(subs []*model.Subscription, atomic bool) ([]error, error)
 	GetByID(id uuid.UUID) (*model.Subscription, error)
-	GetAll() ([]model.Subscription, error)
+	// GetAll возвращает страницу подписок, подходящих под filter, со
+	// статусом на текущий момент.
+	GetAll(filter model.SubscriptionFilter) (*model.SubscriptionPage, error)
+	// Update заменяет подписку целиком; отмена и пауза сохраняются.
 	Update(subscription *model.Subscription) error
+	// Patch частично изменяет подписку: загрузка, слияние с patch, проверка
+	// и сохранение идут в одной транзакции.
+	Patch(id uuid.UUID, patch model.SubscriptionPatch) (*model.Subscription, error)
 	Delete(id uuid.UUID) error
-	GetTotalAmount(userID uuid.UUID, serviceName *string, from, to time.Time) (int, error)
+	// GetTotalAmount возвращает сумму начислений за период в валюте
+	// filter.Currency: price списывается в каждую дату оплаты по циклу
+	// подписки (от start_date до end_date включительно, без end_date —
+	// бессрочно), попавшую в календарные месяцы между From и To, и
+	// пересчитывается по курсу, действующему в месяце списания. Сумма — в
+	// минимальных единицах валюты.
+	GetTotalAmount(filter model.AmountFilter) (int64, error)
+	// GetTotalByService считает то же, что GetTotalAmount, но отдельно по
+	// каждому service_name с долей в общей сумме, по убыванию суммы.
+	GetTotalByService(filter model.AmountFilter) ([]model.ServiceTotal, error)
+	// GetMonthlyAmounts возвращает по одной записи на каждый календарный месяц
+	// между From и To с суммой начислений за этот месяц; при byService сумма
+	// дополнительно разбивается по service_name. Месяцы и сервисы, все
+	// списания которых пришлись на пробный период, помечаются Trial.
+	GetMonthlyAmounts(filter model.AmountFilter, byService bool) ([]model.MonthlyAmount, error)
+	// SchedulePriceChange задаёт цену подписки начиная с change.EffectiveFrom
+	// (в прошлом или будущем); начисления до этой даты не меняются.
+	SchedulePriceChange(change *model.PriceChange) error
+	GetPriceHistory(subscriptionID uuid.UUID) ([]model.PriceChange, error)
+	// GetUpcoming возвращает подписки пользователя, ближайшее списание по
+	// которым приходится на ближайшие withinDays дней, начиная с сегодня.
+	GetUpcoming(userID uuid.UUID, withinDays int) ([]model.UpcomingCharge, error)
+	// GetForecast прогнозирует расходы на months месяцев начиная с текущего
+	// по тем же правилам начислений, что и GetMonthlyAmounts; filter.From и
+	// filter.To игнорируются.
+	GetForecast(filter model.AmountFilter, months int, byService bool) (*model.Forecast, error)
+	// Cancel отменяет подписку с конца периода оплаты, в который попадает
+	// первый день месяца effective (нулевое значение — сегодня): end_date
+	// сдвигается на последний день этого периода, прошлые начисления не
+	// меняются.
+	Cancel(id uuid.UUID, effective time.Time, reason string) (*model.Subscription, error)
+	// Pause приостанавливает списания по подписке с сегодняшнего дня или с
+	// первого дня месяца effective, если он позже. Списания, попавшие на
+	// паузу, не начисляются.
+	Pause(id uuid.UUID, effective time.Time) (*model.Subscription, error)
+	// Resume возобновляет списания с сегодняшнего дня.
+	Resume(id uuid.UUID) (*model.Subscription, error)
+	GetPauses(subscriptionID uuid.UUID) ([]model.Pause, error)
 }
 
+// budgetHorizonMonths — на сколько месяцев вперёд, начиная с текущего,
+// проверяется бюджет при изменении подписки.
+const budgetHorizonMonths = 12
+
+// maxPeriodMonths — самый длинный период отчётов и сумм в месяцах: на
+// каждый месяц периода строится строка ответа и пересчитываются суммы.
+const maxPeriodMonths = 120
+
 type subscriptionService struct {
-	repo repo.SubscriptionRepository
+	repo    repo.SubscriptionRepository
+	budgets repo.BudgetRepository
+	rates   *currency.Rates
 }
 
-func NewSubscriptionService(r repo.SubscriptionRepository) SubscriptionService {
-	return &subscriptionService{repo: r}
+func NewSubscriptionService(r repo.SubscriptionRepository, budgets repo.BudgetRepository, rates *currency.Rates) SubscriptionService {
+	return &subscriptionService{repo: r, budgets: budgets, rates: rates}
 }
 
 func (s *subscriptionService) Create(subscription *model.Subscription) error {
 	log.Printf("Create (service) called: service_name=%v, price=%v, user_id=%v, start_date=%v, end_date=%v", subscription.ServiceName, subscription.Price, subscription.UserID, subscription.StartDate, subscription.EndDate)
-	validator.ValidateSubcription(subscription)
+	if err := s.checkCreate(subscription, nil); err != nil {
+		log.Println("Create (service) error: subscription rejected ", err)
+		return err
+	}
+
+	if err := s.repo.Create(subscription); err != nil {
+		log.Println("Create (service) error: failed to create subscription ", err)
+		return s.overlapError(subscription, err)
+	}
+	withComputed(subscription)
+
+	return nil
+}
+
+func (s *subscriptionService) CreateBatch(subs []*model.Subscription, atomic bool) ([]error, error) {
+	log.Printf("CreateBatch (service) called: count=%d, atomic=%v", len(subs), atomic)
+	errs := make([]error, len(subs))
+	var accepted []*model.Subscription
+	rejected := false
+
+	for i, sub := range subs {
+		if sub == nil {
+			rejected = true
+			continue
+		}
+		if err := s.checkCreate(sub, accepted); err != nil {
+			log.Printf("CreateBatch (service) error: subscription %d rejected: %v", i, err)
+			errs[i] = err
+			rejected = true
+			continue
+		}
+		accepted = append(accepted, sub)
+	}
+
+	if atomic && rejected {
+		log.Println("CreateBatch (service) error: atomic batch rejected")
+		return errs, nil
+	}
+
+	if err := s.repo.CreateBatch(accepted); err != nil {
+		log.Println("CreateBatch (service) error: failed to create subscriptions ", err)
+		if !errors.Is(err, repo.ErrOverlap) {
+			return nil, err
+		}
+		if atomic {
+			return nil, s.batchOverlapError(accepted)
+		}
+
+		// Конкурентный запрос успел создать пересекающуюся подписку:
+		// сохраняем подписки по одной, чтобы отклонить только её.
+		for i, sub := range subs {
+			if sub == nil || errs[i] != nil {
+				continue
+			}
+			if err := s.repo.Create(sub); err != nil {
+				log.Printf("CreateBatch (service) error: failed to create subscription %d: %v", i, err)
+				errs[i] = s.overlapError(sub, err)
+			}
+		}
+	}
+	withComputed(accepted...)
+
+	log.Printf("CreateBatch (service) success: %d of %d subscriptions accepted", len(accepted), len(subs))
+	return errs, nil
+}
+
+// checkCreate проверяет новую подписку перед сохранением: данные,
+// пересечения и бюджет. pending — ещё не сохранённые подписки того же
+// пакета, которые учитываются наравне с сохранёнными.
+func (s *subscriptionService) checkCreate(sub *model.Subscription, pending []*model.Subscription) error {
+	sub.BillingAnchorDay = sub.StartDate.Day()
+	if err := validator.ValidateSubcription(sub); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	if err := s.checkOverlap(sub); err != nil {
+		return err
+	}
+
+	if !sub.AllowOverlap {
+		for _, other := range pending {
+			if !other.AllowOverlap && other.UserID == sub.UserID && other.ServiceName == sub.ServiceName && datesOverlap(sub, other) {
+				return fmt.Errorf("%w: conflicting subscription is earlier in the batch", ErrOverlap)
+			}
+		}
+	}
 
-	return s.repo.Create(subscription)
+	return s.checkBudget(sub, pending...)
 }
 
 func (s *subscriptionService) GetByID(id uuid.UUID) (*model.Subscription, error) {
 	log.Printf("GetByID (service) called: id=%v", id)
 	sub, err := s.repo.GetByID(id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			log.Println("GetByID (service) error: subscription not found ", err)
-			return nil, sql.ErrNoRows
-		}
 		log.Println("GetByID (service) error: failed to get subscription by id ", err)
-		return nil, err
+		return nil, notFound(err, "subscription", id)
 	}
 
+	withComputed(sub)
+
 	log.Println("GetByID (service) success: subscription found ", sub)
 	return sub, nil
 }
 
-func (s *subscriptionService) GetAll() ([]model.Subscription, error) {
-	log.Println("GetAll (service) called")
-	subs, err := s.repo.GetAll()
+func (s *subscriptionService) GetAll(filter model.SubscriptionFilter) (*model.SubscriptionPage, error) {
+	log.Printf("GetAll (service) called: user_id=%v, service_name=%v, status=%v, sort=%v, desc=%v, limit=%v", filter.UserID, filter.ServiceName, filter.Status, filter.Sort, filter.Desc, filter.Limit)
+	filter.At = time.Now()
+
+	page, err := s.repo.GetAll(filter)
 	if err != nil {
 		log.Println("GetAll(service) error: failed to get all subscriptions ", err)
 		return nil, err
 	}
-	log.Printf("GetAll (service) success: found %d subscriptions ", len(subs))
-	return subs, nil
+
+	for i := range page.Items {
+		withComputed(&page.Items[i])
+	}
+	log.Printf("GetAll (service) success: found %d subscriptions ", len(page.Items))
+	return page, nil
 }
 
 func (s *subscriptionService) Update(subscription *model.Subscription) error {
 	log.Printf("Update (service) called: id=%v", subscription.ID)
-	validator.ValidateSubcription(subscription)
-
-	err := s.repo.Update(subscription)
+	// Отмена и пауза меняются отдельными запросами, а не обновлением.
+	updated, err := s.update(subscription.ID, func(current *model.Subscription) {
+		subscription.CancelledAt = current.CancelledAt
+		subscription.CancelReason = current.CancelReason
+		subscription.PausedFrom = current.PausedFrom
+		*current = *subscription
+	})
 	if err != nil {
 		log.Println("Update (service) error: failed to update subscription ", err)
 		return err
 	}
+	*subscription = *updated
 
 	log.Println("Update (service) success: subscription updated")
 	return nil
 }
 
+func (s *subscriptionService) Patch(id uuid.UUID, patch model.SubscriptionPatch) (*model.Subscription, error) {
+	log.Printf("Patch (service) called: id=%v", id)
+	sub, err := s.update(id, func(current *model.Subscription) {
+		applyPatch(current, &patch)
+	})
+	if err != nil {
+		log.Println("Patch (service) error: failed to patch subscription ", err)
+		return nil, err
+	}
+
+	log.Println("Patch (service) success: subscription patched")
+	return sub, nil
+}
+
+// update загружает подписку с блокировкой строки, применяет к ней change,
+// проверяет результат и сохраняет его в одной транзакции repo.Update.
+func (s *subscriptionService) update(id uuid.UUID, change func(sub *model.Subscription)) (*model.Subscription, error) {
+	var merged *model.Subscription
+	sub, err := s.repo.Update(id, func(sub *model.Subscription) error {
+		current := *sub
+		change(sub)
+		if !sameDate(current.EndDate, sub.EndDate) {
+			if err := checkTransition(&current, actionEndDate, time.Now()); err != nil {
+				log.Println("update (service) error: end_date change rejected ", err)
+				return err
+			}
+		}
+		merged = sub
+		sub.BillingAnchorDay = sub.StartDate.Day()
+
+		if err := validator.ValidateSubcription(sub); err != nil {
+			log.Println("update (service) error: invalid subscription ", err)
+			return fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+
+		if err := s.checkOverlap(sub); err != nil {
+			log.Println("update (service) error: overlap check failed ", err)
+			return err
+		}
+
+		if err := s.checkBudget(sub); err != nil {
+			log.Println("update (service) error: budget check failed ", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		if merged != nil {
+			return nil, s.overlapError(merged, err)
+		}
+		return nil, notFound(err, "subscription", id)
+	}
+
+	withComputed(sub)
+	return sub, nil
+}
+
+// applyPatch переносит в подписку поля patch. Смена цикла оплаты на любой,
+// кроме custom, сбрасывает billing_interval, а trial_months отсчитывается от
+// start_date после слияния.
+func applyPatch(sub *model.Subscription, patch *model.SubscriptionPatch) {
+	if patch.ServiceName != nil {
+		sub.ServiceName = *patch.ServiceName
+	}
+	if patch.Price != nil {
+		sub.Price.Amount = *patch.Price
+	}
+	if patch.Currency != nil {
+		sub.Price.Currency = *patch.Currency
+	}
+	if patch.BillingCycle != nil {
+		sub.BillingCycle = *patch.BillingCycle
+		if sub.BillingCycle != model.BillingCustom {
+			sub.BillingInterval = 0
+		}
+	}
+	if patch.BillingInterval != nil {
+		sub.BillingInterval = *patch.BillingInterval
+	}
+	if patch.UserID != nil {
+		sub.UserID = *patch.UserID
+	}
+	if patch.StartDate != nil {
+		sub.StartDate = *patch.StartDate
+	}
+	if patch.ClearEndDate {
+		sub.EndDate = nil
+	} else if patch.EndDate != nil {
+		sub.EndDate = patch.EndDate
+	}
+	switch {
+	case patch.ClearTrial:
+		sub.TrialEndDate = nil
+	case patch.TrialEndDate != nil:
+		sub.TrialEndDate = patch.TrialEndDate
+	case patch.TrialMonths != nil && *patch.TrialMonths == 0:
+		sub.TrialEndDate = nil
+	case patch.TrialMonths != nil:
+		t := utils.AddMonths(sub.StartDate, *patch.TrialMonths).AddDate(0, 0, -1)
+		sub.TrialEndDate = &t
+	}
+	if patch.AllowOverlap != nil {
+		sub.AllowOverlap = *patch.AllowOverlap
+	}
+}
+
 func (s *subscriptionService) Delete(id uuid.UUID) error {
 	log.Printf("Delete (service) called: id=%v", id)
 	err := s.repo.Delete(id)
 	if err != nil {
 		log.Println("Delete (service) error: failed to delete subscription ", err)
-		return err
+		return notFound(err, "subscription", id)
 	}
 
 	log.Println("Delete (service) success: subscription deleted")
 	return nil
 }
 
-func (s *subscriptionService) GetTotalAmount(userID uuid.UUID, serviceName *string, from, to time.Time) (int, error) {
-	log.Printf("GetTotalAmount (service) called: user_id=%v, service_name=%v, from=%v, to=%v", userID, serviceName, from, to)
+func (s *subscriptionService) GetTotalAmount(filter model.AmountFilter) (int64, error) {
+	log.Printf("GetTotalAmount (service) called: user_id=%v, service_name=%v, from=%v, to=%v, currency=%v", filter.UserID, filter.ServiceName, filter.From, filter.To, filter.Currency)
+	charges, err := s.monthlyCharges(filter)
+	if err != nil {
+		log.Println("GetTotalAmount (service) error: failed to get total amount ", err)
+		return 0, err
+	}
+
+	var total int64
+	for _, c := range charges {
+		total += c.Amount
+	}
+
+	log.Printf("GetTotalAmount (service) success: total = %d %s", total, filter.Currency)
+	return total, nil
+}
+
+func (s *subscriptionService) GetTotalByService(filter model.AmountFilter) ([]model.ServiceTotal, error) {
+	log.Printf("GetTotalByService (service) called: user_id=%v, service_name=%v, from=%v, to=%v, currency=%v", filter.UserID, filter.ServiceName, filter.From, filter.To, filter.Currency)
+	charges, err := s.monthlyCharges(filter)
+	if err != nil {
+		log.Println("GetTotalByService (service) error: failed to get totals by service ", err)
+		return nil, err
+	}
+
+	var services []model.ServiceAmount
+	var total int64
+	for _, c := range charges {
+		services = addServiceAmount(services, c)
+		total += c.Amount
+	}
+
+	totals := make([]model.ServiceTotal, 0, len(services))
+	for _, sa := range services {
+		st := model.ServiceTotal{ServiceName: sa.ServiceName, Amount: sa.Amount}
+		if total > 0 {
+			st.Share = math.Round(float64(sa.Amount)/float64(total)*10000) / 10000
+		}
+		totals = append(totals, st)
+	}
+
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].Amount != totals[j].Amount {
+			return totals[i].Amount > totals[j].Amount
+		}
+		return totals[i].ServiceName < totals[j].ServiceName
+	})
+
+	log.Printf("GetTotalByService (service) success: %d services, total = %d %s", len(totals), total, filter.Currency)
+	return totals, nil
+}
+
+func (s *subscriptionService) GetMonthlyAmounts(filter model.AmountFilter, byService bool) ([]model.MonthlyAmount, error) {
+	log.Printf("GetMonthlyAmounts (service) called: user_id=%v, service_name=%v, from=%v, to=%v, currency=%v, by_service=%v", filter.UserID, filter.ServiceName, filter.From, filter.To, filter.Currency, byService)
+	charges, err := s.monthlyCharges(filter)
+	if err != nil {
+		log.Println("GetMonthlyAmounts (service) error: failed to get monthly amounts ", err)
+		return nil, err
+	}
+
+	first := utils.StartOfMonth(filter.From)
+
+	var amounts []model.MonthlyAmount
+	for month := first; !month.After(filter.To); month = month.AddDate(0, 1, 0) {
+		amounts = append(amounts, model.MonthlyAmount{Month: month})
+	}
+
+	charged := make([]bool, len(amounts))
+	for _, c := range charges {
+		i := utils.MonthsBetween(first, c.Month)
+		amount := &amounts[i]
+		amount.Amount += c.Amount
+		amount.Trial = c.Trial && (amount.Trial || !charged[i])
+		charged[i] = true
+		if byService {
+			amount.Services = addServiceAmount(amount.Services, c)
+		}
+	}
+
+	log.Printf("GetMonthlyAmounts (service) success: %d months", len(amounts))
+	return amounts, nil
+}
+
+// monthlyCharges возвращает начисления за период, пересчитанные в
+// filter.Currency по курсу, действующему в месяце начисления.
+func (s *subscriptionService) monthlyCharges(filter model.AmountFilter) ([]model.MonthlyCharge, error) {
+	if err := validatePeriod(filter.From, filter.To); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	if !currency.IsSupported(filter.Currency) {
+		return nil, fmt.Errorf("%w: unsupported currency %q", ErrValidation, filter.Currency)
+	}
+
+	charges, err := s.repo.GetMonthlyCharges(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range charges {
+		amount, err := s.rates.Convert(money.New(c.Amount, c.Currency), filter.Currency, c.Month)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRateUnavailable, err)
+		}
+		charges[i].Amount = amount.Amount
+		charges[i].Currency = filter.Currency
+	}
+
+	return charges, nil
+}
+
+func addServiceAmount(services []model.ServiceAmount, c model.MonthlyCharge) []model.ServiceAmount {
+	for i := range services {
+		if services[i].ServiceName == c.ServiceName {
+			services[i].Amount += c.Amount
+			services[i].Trial = services[i].Trial && c.Trial
+			return services
+		}
+	}
+	return append(services, model.ServiceAmount{ServiceName: c.ServiceName, Amount: c.Amount, Trial: c.Trial})
+}
+
+func (s *subscriptionService) SchedulePriceChange(change *model.PriceChange) error {
+	log.Printf("SchedulePriceChange (service) called: subscription_id=%v, price=%v, effective_from=%v", change.SubscriptionID, change.Price, change.EffectiveFrom)
+	sub, err := s.repo.GetByID(change.SubscriptionID)
+	if err != nil {
+		log.Println("SchedulePriceChange (service) error: failed to get subscription ", err)
+		return notFound(err, "subscription", change.SubscriptionID)
+	}
+
+	if change.Price.Currency == "" {
+		change.Price.Currency = sub.Price.Currency
+	}
+
+	if err := validator.ValidatePriceChange(change, sub); err != nil {
+		log.Println("SchedulePriceChange (service) error: invalid price change ", err)
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	if err := s.repo.AddPriceChange(change); err != nil {
+		log.Println("SchedulePriceChange (service) error: failed to add price change ", err)
+		return err
+	}
+
+	log.Println("SchedulePriceChange (service) success: price change scheduled")
+	return nil
+}
+
+func (s *subscriptionService) GetPriceHistory(subscriptionID uuid.UUID) ([]model.PriceChange, error) {
+	log.Printf("GetPriceHistory (service) called: subscription_id=%v", subscriptionID)
+	if _, err := s.repo.GetByID(subscriptionID); err != nil {
+		log.Println("GetPriceHistory (service) error: failed to get subscription ", err)
+		return nil, notFound(err, "subscription", subscriptionID)
+	}
+
+	prices, err := s.repo.GetPriceHistory(subscriptionID)
+	if err != nil {
+		log.Println("GetPriceHistory (service) error: failed to get price history ", err)
+		return nil, err
+	}
+
+	log.Printf("GetPriceHistory (service) success: found %d prices", len(prices))
+	return prices, nil
+}
+
+func (s *subscriptionService) GetUpcoming(userID uuid.UUID, withinDays int) ([]model.UpcomingCharge, error) {
+	log.Printf("GetUpcoming (service) called: user_id=%v, within_days=%d", userID, withinDays)
+	if withinDays < 0 {
+		log.Println("GetUpcoming (service) error: within_days is negative")
+		return nil, fmt.Errorf("%w: within_days must not be negative", ErrValidation)
+	}
+
+	today := utils.StartOfDay(time.Now())
+	until := today.AddDate(0, 0, withinDays)
+
+	subs, err := s.repo.GetActiveByUser(userID, today)
+	if err != nil {
+		log.Println("GetUpcoming (service) error: failed to get subscriptions ", err)
+		return nil, err
+	}
+
+	var upcoming []model.UpcomingCharge
+
+	for i := range subs {
+		sub := &subs[i]
+
+		next, ok := NextBillingDate(sub, today)
+		if !ok || next.After(until) {
+			continue
+		}
+
+		prices, err := s.repo.GetPriceHistory(sub.ID)
+		if err != nil {
+			log.Println("GetUpcoming (service) error: failed to get price history ", err)
+			return nil, err
+		}
+
+		price := priceAt(sub, prices, next)
+		charge := model.UpcomingCharge{
+			SubscriptionID: sub.ID,
+			ServiceName:    sub.ServiceName,
+			ChargeDate:     next,
+			Currency:       price.Currency,
+			Trial:          InTrial(sub, next),
+		}
+		if !charge.Trial {
+			charge.Amount = price.Amount
+		}
+		upcoming = append(upcoming, charge)
+	}
+
+	sort.Slice(upcoming, func(i, j int) bool { return upcoming[i].ChargeDate.Before(upcoming[j].ChargeDate) })
+
+	log.Printf("GetUpcoming (service) success: found %d upcoming charges", len(upcoming))
+	return upcoming, nil
+}
+
+func (s *subscriptionService) GetForecast(filter model.AmountFilter, months int, byService bool) (*model.Forecast, error) {
+	log.Printf("GetForecast (service) called: user_id=%v, service_name=%v, months=%d, currency=%v", filter.UserID, filter.ServiceName, months, filter.Currency)
+	if months <= 0 {
+		log.Println("GetForecast (service) error: months must be greater than 0")
+		return nil, fmt.Errorf("%w: months must be greater than 0", ErrValidation)
+	}
+
+	filter.From = utils.StartOfMonth(time.Now())
+	filter.To = utils.EndOfMonth(utils.AddMonths(filter.From, months-1))
+
+	amounts, err := s.GetMonthlyAmounts(filter, byService)
+	if err != nil {
+		log.Println("GetForecast (service) error: failed to get monthly amounts ", err)
+		return nil, err
+	}
+
+	forecast := &model.Forecast{Months: amounts}
+	for _, a := range amounts {
+		forecast.Total += a.Amount
+	}
+
+	log.Printf("GetForecast (service) success: total = %d %s", forecast.Total, filter.Currency)
+	return forecast, nil
+}
+
+func (s *subscriptionService) Cancel(id uuid.UUID, effective time.Time, reason string) (*model.Subscription, error) {
+	log.Printf("Cancel (service) called: id=%v, effective=%v, reason=%q", id, effective, reason)
+	sub, err := s.repo.GetByID(id)
+	if err != nil {
+		log.Println("Cancel (service) error: failed to get subscription ", err)
+		return nil, notFound(err, "subscription", id)
+	}
+
+	now := time.Now()
+	at := utils.StartOfDay(now)
+	if !effective.IsZero() {
+		if effective.Before(utils.StartOfMonth(now)) {
+			log.Println("Cancel (service) error: effective month is in the past")
+			return nil, fmt.Errorf("%w: effective month must not be in the past", ErrValidation)
+		}
+		if effective.After(at) {
+			at = effective
+		}
+	}
+
+	if err := checkTransition(sub, actionCancel, now); err != nil {
+		log.Println("Cancel (service) error: ", err)
+		return nil, err
+	}
+
+	if at.Before(sub.StartDate) {
+		log.Println("Cancel (service) error: subscription has not started yet")
+		return nil, fmt.Errorf("%w: subscription starts after the cancellation date, delete it instead", ErrValidation)
+	}
+
+	end, ok := PeriodEnd(sub, at)
+	if !ok {
+		log.Println("Cancel (service) error: failed to find billing period end")
+		return nil, fmt.Errorf("failed to find billing period end for subscription %v", id)
+	}
+	if sub.EndDate == nil || end.Before(*sub.EndDate) {
+		sub.EndDate = &end
+	}
+
+	sub.CancelledAt = &now
+	if reason != "" {
+		sub.CancelReason = &reason
+	}
+
+	if err := s.repo.Cancel(sub); err != nil {
+		log.Println("Cancel (service) error: failed to cancel subscription ", err)
+		return nil, notFound(err, "subscription", id)
+	}
+
+	withComputed(sub)
+
+	log.Printf("Cancel (service) success: subscription ends on %v", sub.EndDate)
+	return sub, nil
+}
+
+func (s *subscriptionService) Pause(id uuid.UUID, effective time.Time) (*model.Subscription, error) {
+	log.Printf("Pause (service) called: id=%v, effective=%v", id, effective)
+	sub, err := s.repo.GetByID(id)
+	if err != nil {
+		log.Println("Pause (service) error: failed to get subscription ", err)
+		return nil, notFound(err, "subscription", id)
+	}
+
+	now := time.Now()
+	from := utils.StartOfDay(now)
+	if !effective.IsZero() {
+		if effective.Before(utils.StartOfMonth(now)) {
+			log.Println("Pause (service) error: effective month is in the past")
+			return nil, fmt.Errorf("%w: effective month must not be in the past", ErrValidation)
+		}
+		if effective.After(from) {
+			from = effective
+		}
+	}
+
+	if err := checkTransition(sub, actionPause, now); err != nil {
+		log.Println("Pause (service) error: ", err)
+		return nil, err
+	}
+
+	if sub.PausedFrom != nil {
+		log.Println("Pause (service) error: pause is already scheduled")
+		return nil, &TransitionError{Action: actionPause, Status: Status(sub, now), Reason: "a pause is already scheduled"}
+	}
+
+	if sub.EndDate != nil && sub.EndDate.Before(from) {
+		log.Println("Pause (service) error: subscription ends before the pause")
+		return nil, &TransitionError{Action: actionPause, Status: Status(sub, now), Reason: "subscription ends before the pause would start"}
+	}
+
+	pause := model.Pause{SubscriptionID: id, PausedFrom: from}
+	if err := s.repo.AddPause(&pause); err != nil {
+		log.Println("Pause (service) error: failed to add pause ", err)
+		if errors.Is(err, repo.ErrPauseExists) {
+			return nil, &TransitionError{Action: actionPause, Status: Status(sub, now), Reason: "a pause is already scheduled"}
+		}
+		return nil, err
+	}
+	sub.PausedFrom = &pause.PausedFrom
+	withComputed(sub)
+
+	log.Printf("Pause (service) success: subscription paused from %v", from)
+	return sub, nil
+}
+
+func (s *subscriptionService) Resume(id uuid.UUID) (*model.Subscription, error) {
+	log.Printf("Resume (service) called: id=%v", id)
+	sub, err := s.repo.GetByID(id)
+	if err != nil {
+		log.Println("Resume (service) error: failed to get subscription ", err)
+		return nil, notFound(err, "subscription", id)
+	}
+
+	// Запланированную, но ещё не начавшуюся паузу тоже можно снять
+	// возобновлением.
+	now := time.Now()
+	status := Status(sub, now)
+	scheduled := sub.PausedFrom != nil && (status == model.StatusTrial || status == model.StatusActive)
+	if !scheduled {
+		if err := checkTransition(sub, actionResume, now); err != nil {
+			log.Println("Resume (service) error: ", err)
+			return nil, err
+		}
+	}
+
+	pause, err := s.repo.Resume(id, utils.StartOfDay(now))
+	if err != nil {
+		log.Println("Resume (service) error: failed to resume subscription ", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &TransitionError{Action: actionResume, Status: status, Reason: "subscription is not paused"}
+		}
+		return nil, err
+	}
+	sub.PausedFrom = nil
+	withComputed(sub)
+
+	log.Printf("Resume (service) success: subscription resumed on %v", pause.ResumedOn)
+	return sub, nil
+}
+
+func (s *subscriptionService) GetPauses(subscriptionID uuid.UUID) ([]model.Pause, error) {
+	log.Printf("GetPauses (service) called: subscription_id=%v", subscriptionID)
+	if _, err := s.repo.GetByID(subscriptionID); err != nil {
+		log.Println("GetPauses (service) error: failed to get subscription ", err)
+		return nil, notFound(err, "subscription", subscriptionID)
+	}
+
+	pauses, err := s.repo.GetPauses(subscriptionID)
+	if err != nil {
+		log.Println("GetPauses (service) error: failed to get pauses ", err)
+		return nil, err
+	}
+
+	log.Printf("GetPauses (service) success: found %d pauses", len(pauses))
+	return pauses, nil
+}
+
+// checkBudget отклоняет создание или изменение подписки, если у
+// пользователя включён enforce бюджета и в одном из ближайших
+// budgetHorizonMonths месяцев расходы с учётом изменения превысят бюджет
+// и окажутся больше, чем без него. Для новой подписки расходы по
+// несохранённым подпискам пользователя из pending добавляются к уже
+// сохранённым.
+func (s *subscriptionService) checkBudget(sub *model.Subscription, pending ...*model.Subscription) error {
+	budget, err := s.budgets.GetByUserID(sub.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !budget.Enforce {
+		return nil
+	}
+
+	from := utils.StartOfMonth(time.Now())
+	filter := model.AmountFilter{
+		UserID:   sub.UserID,
+		From:     from,
+		To:       utils.EndOfMonth(utils.AddMonths(from, budgetHorizonMonths-1)),
+		Currency: budget.Amount.Currency,
+	}
+
+	before, err := s.GetMonthlyAmounts(filter, false)
+	if err != nil {
+		return err
+	}
+	for _, other := range pending {
+		if other.UserID != sub.UserID {
+			continue
+		}
+		if err := s.addCharges(before, other, filter.From, filter.To, filter.Currency); err != nil {
+			return err
+		}
+	}
+
+	after := append([]model.MonthlyAmount(nil), before...)
+	if sub.ID != uuid.Nil {
+		filter.ExcludeID = &sub.ID
+		after, err = s.GetMonthlyAmounts(filter, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.addCharges(after, sub, filter.From, filter.To, filter.Currency); err != nil {
+		return err
+	}
+
+	for i, a := range after {
+		if a.Amount > budget.Amount.Amount && a.Amount > before[i].Amount {
+			spent := money.New(a.Amount, budget.Amount.Currency)
+			return fmt.Errorf("%w: spending in %s would be %s %s with a budget of %s %s",
+				ErrBudgetExceeded, utils.FormatDate(a.Month), spent, spent.Currency, budget.Amount, budget.Amount.Currency)
+		}
+	}
+
+	return nil
+}
+
+// addCharges прибавляет к помесячным суммам amounts в валюте cur,
+// начинающимся с месяца from, списания подписки sub между from и to. До
+// первого запланированного изменения цены действует sub.Price — цена,
+// которую сохранение подписки запишет в историю с сегодняшнего дня, —
+// дальше цены из subscription_prices, как в monthly_amount.
+func (s *subscriptionService) addCharges(amounts []model.MonthlyAmount, sub *model.Subscription, from, to time.Time, cur string) error {
+	var scheduled []model.PriceChange
+	if sub.ID != uuid.Nil {
+		prices, err := s.repo.GetPriceHistory(sub.ID)
+		if err != nil {
+			return err
+		}
+		today := utils.StartOfDay(time.Now())
+		for _, p := range prices {
+			if p.EffectiveFrom.After(today) {
+				scheduled = append(scheduled, p)
+			}
+		}
+	}
+
+	for _, date := range chargeDates(sub, from, to) {
+		if InTrial(sub, date) {
+			continue
+		}
+		amount, err := s.rates.Convert(priceAt(sub, scheduled, date), cur, utils.StartOfMonth(date))
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRateUnavailable, err)
+		}
+		amounts[utils.MonthsBetween(from, date)].Amount += amount.Amount
+	}
+	return nil
+}
+
+// datesOverlap сообщает, пересекаются ли даты подписок a и b: end_date
+// входит в подписку, без end_date подписка бессрочна.
+func datesOverlap(a, b *model.Subscription) bool {
+	return (b.EndDate == nil || !a.StartDate.After(*b.EndDate)) &&
+		(a.EndDate == nil || !b.StartDate.After(*a.EndDate))
+}
+
+// checkOverlap отклоняет подписку без AllowOverlap, если у пользователя
+// уже есть подписка на тот же сервис на пересекающиеся даты.
+func (s *subscriptionService) checkOverlap(sub *model.Subscription) error {
+	if sub.AllowOverlap {
+		return nil
+	}
+
+	other, err := s.repo.GetOverlapping(sub)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return &OverlapError{ConflictingID: other.ID}
+}
+
+// overlapError превращает repo.ErrOverlap — конкурентный запрос успел
+// записать пересекающуюся подписку после checkOverlap — в OverlapError.
+// Остальные ошибки возвращаются без изменений.
+func (s *subscriptionService) overlapError(sub *model.Subscription, err error) error {
+	if !errors.Is(err, repo.ErrOverlap) {
+		return err
+	}
+
+	if err := s.checkOverlap(sub); err != nil {
+		return err
+	}
+
+	// Пересекающуюся подписку успели удалить или изменить — сообщить её id
+	// уже нельзя.
+	return ErrOverlap
+}
+
+// batchOverlapError ищет среди подписок атомарного пакета ту, что
+// пересеклась с записанной конкурентным запросом, и возвращает
+// OverlapError с id этой записи.
+func (s *subscriptionService) batchOverlapError(subs []*model.Subscription) error {
+	for _, sub := range subs {
+		if err := s.checkOverlap(sub); err != nil {
+			return err
+		}
+	}
+
+	return ErrOverlap
+}
+
+func validatePeriod(from, to time.Time) error {
 	if from.IsZero() || to.IsZero() {
-		log.Println("GetTotalAmount (service) error: from or to is zero")
-		return 0, errors.New("date range is required")
+		return errors.New("date range is required")
 	}
 
 	if from.After(to) {
-		log.Println("GetTotalAmount (service) error: from is after to")
-		return 0, errors.New("invalid date range: 'from' is after 'to'")
+		return errors.New("invalid date range: 'from' is after 'to'")
 	}
 
-	total, err := s.repo.GetTotalAmount(userID, serviceName, from, to)
-	if err != nil {
-		log.Println("GetTotalAmount (service) error: failed to get total amount ", err)
-		return 0, err
+	if utils.MonthsBetween(from, to) >= maxPeriodMonths {
+		return fmt.Errorf("date range must not exceed %d months", maxPeriodMonths)
 	}
 
-	log.Printf("GetTotalAmount (service) success: total = %d", total)
-	return total, nil
+	return nil
 }
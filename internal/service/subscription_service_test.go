@@ -0,0 +1,272 @@
+package service
+
+import (
+	"errors"
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/internal/repo"
+	"go-subscriptions-service/pgk/currency"
+	"go-subscriptions-service/pgk/money"
+	"go-subscriptions-service/pgk/utils"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// stubSubscriptionRepo отдаёт заранее заданные начисления и запоминает
+// фильтр, с которым их запросили. Остальные методы не реализованы.
+type stubSubscriptionRepo struct {
+	repo.SubscriptionRepository
+	charges []model.MonthlyCharge
+	filter  model.AmountFilter
+}
+
+func (r *stubSubscriptionRepo) GetMonthlyCharges(filter model.AmountFilter) ([]model.MonthlyCharge, error) {
+	r.filter = filter
+	return append([]model.MonthlyCharge(nil), r.charges...), nil
+}
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := utils.ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func testRates(t *testing.T) *currency.Rates {
+	rates := currency.NewRates()
+	rates.Add("USD", date(t, "2024-01-01"), big.NewRat(90, 1))
+	return rates
+}
+
+func TestGetMonthlyAmounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		currency string
+		charges  []model.MonthlyCharge
+		want     map[string]int64
+	}{
+		{
+			name: "subscription started before from",
+			from: "2024-03-01", to: "2024-05-01", currency: "RUB",
+			charges: []model.MonthlyCharge{
+				{Month: date(t, "2024-03-01"), ServiceName: "netflix", Currency: "RUB", Amount: 1000},
+				{Month: date(t, "2024-04-01"), ServiceName: "netflix", Currency: "RUB", Amount: 1000},
+				{Month: date(t, "2024-05-01"), ServiceName: "netflix", Currency: "RUB", Amount: 1000},
+			},
+			want: map[string]int64{"2024-03-01": 1000, "2024-04-01": 1000, "2024-05-01": 1000},
+		},
+		{
+			name: "months without charges are zero",
+			from: "2024-01-01", to: "2024-04-01", currency: "RUB",
+			charges: []model.MonthlyCharge{
+				{Month: date(t, "2024-02-01"), ServiceName: "netflix", Currency: "RUB", Amount: 1000},
+			},
+			want: map[string]int64{"2024-01-01": 0, "2024-02-01": 1000, "2024-03-01": 0, "2024-04-01": 0},
+		},
+		{
+			name: "end month is counted",
+			from: "2024-01-01", to: "2024-04-01", currency: "RUB",
+			charges: []model.MonthlyCharge{
+				{Month: date(t, "2024-04-01"), ServiceName: "netflix", Currency: "RUB", Amount: 1000},
+			},
+			want: map[string]int64{"2024-01-01": 0, "2024-02-01": 0, "2024-03-01": 0, "2024-04-01": 1000},
+		},
+		{
+			name: "single-month range",
+			from: "2024-02-01", to: "2024-02-01", currency: "RUB",
+			charges: []model.MonthlyCharge{
+				{Month: date(t, "2024-02-01"), ServiceName: "netflix", Currency: "RUB", Amount: 1000},
+				{Month: date(t, "2024-02-01"), ServiceName: "spotify", Currency: "RUB", Amount: 500},
+			},
+			want: map[string]int64{"2024-02-01": 1500},
+		},
+		{
+			name: "charges are converted at the month rate",
+			from: "2024-01-01", to: "2024-01-01", currency: "RUB",
+			charges: []model.MonthlyCharge{
+				{Month: date(t, "2024-01-01"), ServiceName: "netflix", Currency: "USD", Amount: 1000},
+			},
+			want: map[string]int64{"2024-01-01": 90000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubSubscriptionRepo{charges: tt.charges}
+			s := &subscriptionService{repo: stub, rates: testRates(t)}
+
+			filter := model.AmountFilter{
+				UserID:   uuid.New(),
+				From:     date(t, tt.from),
+				To:       date(t, tt.to),
+				Currency: tt.currency,
+			}
+			amounts, err := s.GetMonthlyAmounts(filter, false)
+			if err != nil {
+				t.Fatalf("GetMonthlyAmounts() error = %v", err)
+			}
+
+			if !stub.filter.From.Equal(filter.From) || !stub.filter.To.Equal(filter.To) {
+				t.Errorf("repo got period %v - %v, want %v - %v", stub.filter.From, stub.filter.To, filter.From, filter.To)
+			}
+
+			got := make(map[string]int64, len(amounts))
+			for _, a := range amounts {
+				got[utils.FormatDate(a.Month)] = a.Amount
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d months %v, want %d %v", len(got), got, len(tt.want), tt.want)
+			}
+			for month, want := range tt.want {
+				if got[month] != want {
+					t.Errorf("month %s: got %d, want %d", month, got[month], want)
+				}
+			}
+		})
+	}
+}
+
+func TestMonthlyChargesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		currency string
+		charges  []model.MonthlyCharge
+		want     error
+	}{
+		{name: "missing period", from: "", to: "2024-01-01", currency: "RUB", want: ErrValidation},
+		{name: "from after to", from: "2024-02-01", to: "2024-01-01", currency: "RUB", want: ErrValidation},
+		{name: "period over the limit", from: "2014-01-01", to: "2024-01-01", currency: "RUB", want: ErrValidation},
+		{name: "unsupported currency", from: "2024-01-01", to: "2024-01-01", currency: "GBP", want: ErrValidation},
+		{
+			name: "no rate for the month",
+			from: "2023-12-01", to: "2023-12-01", currency: "RUB",
+			charges: []model.MonthlyCharge{
+				{Month: date(t, "2023-12-01"), ServiceName: "netflix", Currency: "USD", Amount: 1000},
+			},
+			want: ErrRateUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &subscriptionService{repo: &stubSubscriptionRepo{charges: tt.charges}, rates: testRates(t)}
+
+			filter := model.AmountFilter{UserID: uuid.New(), To: date(t, tt.to), Currency: tt.currency}
+			if tt.from != "" {
+				filter.From = date(t, tt.from)
+			}
+
+			_, err := s.monthlyCharges(filter)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("monthlyCharges() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestApplyPatch(t *testing.T) {
+	base := func() model.Subscription {
+		return model.Subscription{
+			ServiceName:     "netflix",
+			Price:           money.New(1000, "RUB"),
+			BillingCycle:    model.BillingCustom,
+			BillingInterval: 2,
+			UserID:          uuid.MustParse("d24e286e-fae2-4945-9c90-f124a84d4831"),
+			StartDate:       date(t, "2024-01-10"),
+			EndDate:         ptr(date(t, "2024-12-31")),
+			TrialEndDate:    ptr(date(t, "2024-02-09")),
+		}
+	}
+
+	tests := []struct {
+		name  string
+		patch model.SubscriptionPatch
+		want  func(sub *model.Subscription)
+	}{
+		{
+			name:  "empty patch",
+			patch: model.SubscriptionPatch{},
+			want:  func(sub *model.Subscription) {},
+		},
+		{
+			name:  "price keeps currency",
+			patch: model.SubscriptionPatch{Price: ptr(int64(1500))},
+			want:  func(sub *model.Subscription) { sub.Price = money.New(1500, "RUB") },
+		},
+		{
+			name:  "currency keeps price",
+			patch: model.SubscriptionPatch{Currency: ptr("USD")},
+			want:  func(sub *model.Subscription) { sub.Price = money.New(1000, "USD") },
+		},
+		{
+			name:  "non-custom cycle resets interval",
+			patch: model.SubscriptionPatch{BillingCycle: ptr(model.BillingMonthly)},
+			want: func(sub *model.Subscription) {
+				sub.BillingCycle = model.BillingMonthly
+				sub.BillingInterval = 0
+			},
+		},
+		{
+			name:  "interval without cycle",
+			patch: model.SubscriptionPatch{BillingInterval: ptr(3)},
+			want:  func(sub *model.Subscription) { sub.BillingInterval = 3 },
+		},
+		{
+			name:  "null end_date",
+			patch: model.SubscriptionPatch{ClearEndDate: true},
+			want:  func(sub *model.Subscription) { sub.EndDate = nil },
+		},
+		{
+			name:  "new end_date",
+			patch: model.SubscriptionPatch{EndDate: ptr(date(t, "2025-06-30"))},
+			want:  func(sub *model.Subscription) { sub.EndDate = ptr(date(t, "2025-06-30")) },
+		},
+		{
+			name:  "null trial_end_date",
+			patch: model.SubscriptionPatch{ClearTrial: true},
+			want:  func(sub *model.Subscription) { sub.TrialEndDate = nil },
+		},
+		{
+			name:  "trial_months 0 removes trial",
+			patch: model.SubscriptionPatch{TrialMonths: ptr(0)},
+			want:  func(sub *model.Subscription) { sub.TrialEndDate = nil },
+		},
+		{
+			name:  "trial_months counts from the new start_date",
+			patch: model.SubscriptionPatch{StartDate: ptr(date(t, "2024-01-31")), TrialMonths: ptr(1)},
+			want: func(sub *model.Subscription) {
+				sub.StartDate = date(t, "2024-01-31")
+				sub.TrialEndDate = ptr(date(t, "2024-02-28"))
+			},
+		},
+		{
+			name:  "allow_overlap",
+			patch: model.SubscriptionPatch{AllowOverlap: ptr(true)},
+			want:  func(sub *model.Subscription) { sub.AllowOverlap = true },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := base()
+			applyPatch(&got, &tt.patch)
+
+			want := base()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("applyPatch() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
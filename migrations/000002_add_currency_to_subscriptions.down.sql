@@ -0,0 +1 @@
+ALTER TABLE subscriptions DROP COLUMN if exists currency;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE subscriptions ADD COLUMN currency text not null default 'RUB';
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    DROP COLUMN if exists billing_interval,
+    DROP COLUMN if exists billing_cycle;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE subscriptions
+    ADD COLUMN billing_cycle text not null default 'monthly',
+    ADD COLUMN billing_interval int not null default 0,
+    ADD CONSTRAINT subscriptions_billing_cycle_check
+        CHECK (billing_cycle IN ('weekly', 'monthly', 'quarterly', 'yearly', 'custom')),
+    ADD CONSTRAINT subscriptions_billing_interval_check
+        CHECK (billing_cycle <> 'custom' OR billing_interval > 0);
\ No newline at end of file
@@ -0,0 +1 @@
+drop table if exists subscription_prices;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE table subscription_prices (
+    id uuid primary key default gen_random_uuid(),
+    subscription_id uuid not null references subscriptions (id) on delete cascade,
+    price int not null,
+    effective_from date not null,
+    created_at timestamptz not null default now(),
+    unique (subscription_id, effective_from)
+);
+
+INSERT INTO subscription_prices (subscription_id, price, effective_from)
+SELECT id, price, start_date FROM subscriptions;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE subscriptions DROP COLUMN if exists trial_end_date;
\ No newline at end of file
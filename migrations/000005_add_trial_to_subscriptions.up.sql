@@ -0,0 +1 @@
+ALTER TABLE subscriptions ADD COLUMN trial_end_date date;
\ No newline at end of file
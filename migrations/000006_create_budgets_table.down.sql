@@ -0,0 +1 @@
+drop table if exists budgets;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE table budgets (
+    user_id uuid primary key,
+    amount int not null check (amount > 0),
+    currency text not null default 'RUB',
+    enforce boolean not null default false,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions ALTER COLUMN price TYPE int USING round(price / 100.0)::int;
+ALTER TABLE subscription_prices ALTER COLUMN price TYPE int USING round(price / 100.0)::int;
+ALTER TABLE budgets ALTER COLUMN amount TYPE int USING round(amount / 100.0)::int;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions ALTER COLUMN price TYPE bigint USING price::bigint * 100;
+ALTER TABLE subscription_prices ALTER COLUMN price TYPE bigint USING price::bigint * 100;
+ALTER TABLE budgets ALTER COLUMN amount TYPE bigint USING amount::bigint * 100;
\ No newline at end of file
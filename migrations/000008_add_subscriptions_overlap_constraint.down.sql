@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions DROP CONSTRAINT if exists subscriptions_no_overlap;
+ALTER TABLE subscriptions DROP COLUMN if exists allow_overlap;
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE subscriptions ADD COLUMN allow_overlap boolean not null default false;
+
+-- Уже пересекающиеся подписки помечаются допустимыми, чтобы ограничение
+-- можно было создать на существующих данных.
+UPDATE subscriptions s
+SET allow_overlap = true
+WHERE EXISTS (
+    SELECT 1
+    FROM subscriptions o
+    WHERE o.id <> s.id
+    AND o.user_id = s.user_id
+    AND o.service_name = s.service_name
+    AND daterange(o.start_date, o.end_date, '[]') && daterange(s.start_date, s.end_date, '[]')
+);
+
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_no_overlap EXCLUDE USING gist (
+    user_id WITH =,
+    service_name WITH =,
+    daterange(start_date, end_date, '[]') WITH &&
+) WHERE (NOT allow_overlap);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    DROP COLUMN if exists cancelled_at,
+    DROP COLUMN if exists cancel_reason;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    ADD COLUMN cancelled_at timestamptz,
+    ADD COLUMN cancel_reason text;
\ No newline at end of file
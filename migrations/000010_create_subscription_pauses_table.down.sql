@@ -0,0 +1 @@
+drop table if exists subscription_pauses;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE table subscription_pauses (
+    id uuid primary key default gen_random_uuid(),
+    subscription_id uuid not null references subscriptions (id) on delete cascade,
+    paused_from date not null,
+    resumed_on date check (resumed_on >= paused_from),
+    created_at timestamptz not null default now()
+);
+
+-- У подписки может быть только одна открытая пауза.
+CREATE UNIQUE INDEX subscription_pauses_open_idx ON subscription_pauses (subscription_id) WHERE resumed_on IS NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE subscriptions DROP COLUMN if exists billing_anchor_day;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE subscriptions ADD COLUMN billing_anchor_day smallint;
+
+UPDATE subscriptions SET billing_anchor_day = date_part('day', start_date);
+
+ALTER TABLE subscriptions
+    ALTER COLUMN billing_anchor_day SET NOT NULL,
+    ADD CONSTRAINT subscriptions_billing_anchor_day_check CHECK (billing_anchor_day BETWEEN 1 AND 31);
\ No newline at end of file
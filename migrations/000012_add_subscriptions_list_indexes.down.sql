@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS subscriptions_user_id_start_date_id_idx;
+DROP INDEX IF EXISTS subscriptions_service_name_id_idx;
+DROP INDEX IF EXISTS subscriptions_price_id_idx;
+DROP INDEX IF EXISTS subscriptions_start_date_id_idx;
\ No newline at end of file
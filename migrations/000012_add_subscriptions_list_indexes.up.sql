@@ -0,0 +1,6 @@
+-- Индексы под сортировку списка подписок с пагинацией по курсору (поле сортировки, id)
+-- и под фильтр по пользователю.
+CREATE INDEX subscriptions_start_date_id_idx ON subscriptions (start_date, id);
+CREATE INDEX subscriptions_price_id_idx ON subscriptions (price, id);
+CREATE INDEX subscriptions_service_name_id_idx ON subscriptions (service_name, id);
+CREATE INDEX subscriptions_user_id_start_date_id_idx ON subscriptions (user_id, start_date, id);
\ No newline at end of file
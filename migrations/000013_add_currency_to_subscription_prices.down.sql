@@ -0,0 +1 @@
+ALTER TABLE subscription_prices DROP COLUMN if exists currency;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Валюта каждой цены из истории: смена валюты подписки не должна менять
+-- суммы за прошлые месяцы.
+ALTER TABLE subscription_prices ADD COLUMN currency text;
+UPDATE subscription_prices sp SET currency = s.currency FROM subscriptions s WHERE s.id = sp.subscription_id;
+ALTER TABLE subscription_prices ALTER COLUMN currency SET NOT NULL;
\ No newline at end of file
@@ -0,0 +1,22 @@
+package currency
+
+import "strings"
+
+// Default — базовая валюта сервиса: в ней хранятся курсы и в неё
+// пересчитываются суммы, если валюта отчёта не указана.
+const Default = "RUB"
+
+var supported = []string{"RUB", "USD", "EUR"}
+
+func IsSupported(code string) bool {
+	for _, c := range supported {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func SupportedList() string {
+	return strings.Join(supported, ", ")
+}
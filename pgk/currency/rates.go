@@ -0,0 +1,126 @@
+package currency
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go-subscriptions-service/pgk/money"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+type rate struct {
+	date  time.Time
+	value *big.Rat
+}
+
+// Rates хранит курсы валют к базовой валюте Default по датам, с которых
+// они действуют. Курсы хранятся точными дробями, чтобы пересчёт сумм не
+// накапливал ошибку float64.
+type Rates struct {
+	rates map[string][]rate
+}
+
+func NewRates() *Rates {
+	return &Rates{rates: make(map[string][]rate)}
+}
+
+// LoadCSV читает курсы из CSV со строками вида "date,currency,rate",
+// где date в формате YYYY-MM-DD, а rate — стоимость одной единицы валюты
+// в Default. Строка заголовка допускается.
+func LoadCSV(path string) (*Rates, error) {
+	log.Println("LoadCSV (currency): loading rates from ", path)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rates file: %v", err)
+	}
+	defer f.Close()
+
+	r := NewRates()
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rates file: %v", err)
+		}
+
+		if line == 1 && strings.EqualFold(record[0], "date") {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q", line, record[0])
+		}
+
+		code := strings.ToUpper(record[1])
+		if !IsSupported(code) {
+			return nil, fmt.Errorf("line %d: unsupported currency %q", line, record[1])
+		}
+
+		value, ok := new(big.Rat).SetString(record[2])
+		if !ok || value.Sign() <= 0 {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, record[2])
+		}
+
+		r.Add(code, date, value)
+	}
+
+	log.Printf("LoadCSV (currency) success: loaded rates for %d currencies", len(r.rates))
+	return r, nil
+}
+
+func (r *Rates) Add(code string, date time.Time, value *big.Rat) {
+	rates := append(r.rates[code], rate{date: date, value: value})
+	sort.Slice(rates, func(i, j int) bool { return rates[i].date.Before(rates[j].date) })
+	r.rates[code] = rates
+}
+
+// Rate возвращает курс валюты к Default, действующий на дату at.
+func (r *Rates) Rate(code string, at time.Time) (*big.Rat, error) {
+	if code == Default {
+		return big.NewRat(1, 1), nil
+	}
+
+	rates := r.rates[code]
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].date.After(at) })
+	if i == 0 {
+		return nil, fmt.Errorf("no %s exchange rate in effect on %s", code, at.Format("2006-01-02"))
+	}
+
+	return rates[i-1].value, nil
+}
+
+// Convert пересчитывает сумму m в валюту to по курсам, действующим на
+// дату at, с округлением до минимальной единицы валюты.
+func (r *Rates) Convert(m money.Money, to string, at time.Time) (money.Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	fromRate, err := r.Rate(m.Currency, at)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	toRate, err := r.Rate(to, at)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	amount := new(big.Rat).SetInt64(m.Amount)
+	amount.Mul(amount, fromRate).Quo(amount, toRate)
+
+	return money.New(money.Round(amount), to), nil
+}
@@ -0,0 +1,148 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money — сумма в минимальных единицах валюты (копейках, центах) с кодом
+// валюты. Вся арифметика целочисленная, поэтому суммы не теряют точность.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// minorDigits — число знаков после запятой у поддерживаемых валют.
+var minorDigits = map[string]int{
+	"RUB": 2,
+	"USD": 2,
+	"EUR": 2,
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func digits(currency string) int {
+	if d, ok := minorDigits[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// Parse разбирает десятичную строку вида "199.90" или "199" в сумму в
+// валюте currency. Знаков после точки не может быть больше, чем у валюты.
+func Parse(s, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	d := digits(currency)
+	if whole == "" || len(frac) > d || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	units, err := strconv.ParseInt(whole+frac+strings.Repeat("0", d-len(frac)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q: %v", s, err)
+	}
+
+	if neg {
+		units = -units
+	}
+	return Money{Amount: units, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String форматирует сумму десятичной строкой без кода валюты: "199.90".
+func (m Money) String() string {
+	d := digits(m.Currency)
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	s := strconv.FormatInt(amount, 10)
+	if d == 0 {
+		return sign + s
+	}
+	if len(s) <= d {
+		s = strings.Repeat("0", d-len(s)+1) + s
+	}
+	return sign + s[:len(s)-d] + "." + s[len(s)-d:]
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Add складывает суммы в одной валюте.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, errors.New("cannot add amounts in different currencies")
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// MulRat умножает сумму на num/den с округлением до минимальной единицы
+// (половина — от нуля).
+func (m Money) MulRat(num, den int64) Money {
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num)), big.NewInt(den))
+	return Money{Amount: Round(r), Currency: m.Currency}
+}
+
+// Round округляет дробь до целого, половину — от нуля.
+func Round(r *big.Rat) int64 {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
+
+type jsonMoney struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMoney{Amount: m.String(), Currency: m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var j jsonMoney
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	parsed, err := Parse(j.Amount, j.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,42 @@
+package money
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "199.90", want: 19990},
+		{in: "199", want: 19900},
+		{in: "0.5", want: 50},
+		{in: "0.05", want: 5},
+		{in: "-1.25", want: -125},
+		{in: " 10.00 ", want: 1000},
+		{in: "", wantErr: true},
+		{in: ".5", wantErr: true},
+		{in: "1.234", wantErr: true},
+		{in: "1,5", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in, "RUB")
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %v, want error", tt.in, got.Amount)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got.Amount != tt.want || got.Currency != "RUB" {
+			t.Errorf("Parse(%q) = %d %s, want %d RUB", tt.in, got.Amount, got.Currency, tt.want)
+		}
+	}
+}
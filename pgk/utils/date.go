@@ -1,11 +1,109 @@
 package utils
 
 import (
-	"log"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 )
 
-func ParseMonthYear(s string) (time.Time, error) {
-	log.Println("parseMonthYear (handler): called with s=", s)
-	return time.Parse("01-2006", s)
+// Форматы дат API. Во входных данных (телах запросов и query-параметрах)
+// принимаются оба, в ответах даты всегда выводятся в DateLayout.
+const (
+	DateLayout  = "2006-01-02"
+	monthLayout = "01-2006"
+)
+
+// ParseDate разбирает дату в формате YYYY-MM-DD или, для совместимости,
+// MM-YYYY (первое число месяца).
+func ParseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(DateLayout, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(monthLayout, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or MM-YYYY)", s)
+}
+
+// ParseMonth разбирает дату так же, как ParseDate, и приводит её к первому
+// числу месяца — для параметров, где важен только месяц: месяц отмены,
+// паузы, новой цены и границы помесячных отчётов.
+func ParseMonth(s string) (time.Time, error) {
+	t, err := ParseDate(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return StartOfMonth(t), nil
+}
+
+// FormatDate выводит дату в формате ответов API (YYYY-MM-DD).
+func FormatDate(t time.Time) string {
+	return t.Format(DateLayout)
+}
+
+// Date — дата без времени для JSON: сериализуется как "YYYY-MM-DD", а при
+// разборе принимает оба формата ParseDate.
+type Date time.Time
+
+func NewDate(t *time.Time) *Date {
+	if t == nil {
+		return nil
+	}
+	d := Date(*t)
+	return &d
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(FormatDate(time.Time(d)))
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.New("invalid date (expected YYYY-MM-DD or MM-YYYY)")
+	}
+	t, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = Date(t)
+	return nil
+}
+
+func StartOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func EndOfMonth(t time.Time) time.Time {
+	return StartOfMonth(t).AddDate(0, 1, -1)
+}
+
+func MonthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+}
+
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// AnchorDate возвращает дату с днём day в месяце month; если в месяце
+// меньше дней, берётся последний: день 31 в феврале — 28 (29) февраля.
+func AnchorDate(month time.Time, day int) time.Time {
+	first := StartOfMonth(month)
+	if last := EndOfMonth(first).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// AddMonths сдвигает дату на months месяцев, прижимая день к концу месяца:
+// 31 января + 1 месяц = 28 (29) февраля. Так же даты сдвигает PostgreSQL.
+func AddMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	day := t.Day()
+	if last := EndOfMonth(first).Day(); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
 }
@@ -0,0 +1,57 @@
+package utils
+
+import "testing"
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		date   string
+		months int
+		want   string
+	}{
+		{"2024-01-15", 1, "2024-02-15"},
+		{"2024-01-31", 1, "2024-02-29"},
+		{"2023-01-31", 1, "2023-02-28"},
+		{"2024-03-31", 1, "2024-04-30"},
+		{"2024-11-30", 3, "2025-02-28"},
+		{"2024-12-31", 12, "2025-12-31"},
+		{"2024-02-29", 12, "2025-02-28"},
+		{"2024-03-31", -1, "2024-02-29"},
+		{"2024-05-10", 0, "2024-05-10"},
+	}
+
+	for _, tt := range tests {
+		d, err := ParseDate(tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FormatDate(AddMonths(d, tt.months)); got != tt.want {
+			t.Errorf("AddMonths(%s, %d) = %s, want %s", tt.date, tt.months, got, tt.want)
+		}
+	}
+}
+
+func TestAnchorDate(t *testing.T) {
+	tests := []struct {
+		month string
+		day   int
+		want  string
+	}{
+		{"2024-03-01", 15, "2024-03-15"},
+		{"2024-03-20", 15, "2024-03-15"},
+		{"2024-02-01", 31, "2024-02-29"},
+		{"2023-02-01", 29, "2023-02-28"},
+		{"2024-04-01", 31, "2024-04-30"},
+		{"2024-01-01", 31, "2024-01-31"},
+		{"2024-06-01", 1, "2024-06-01"},
+	}
+
+	for _, tt := range tests {
+		month, err := ParseDate(tt.month)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FormatDate(AnchorDate(month, tt.day)); got != tt.want {
+			t.Errorf("AnchorDate(%s, %d) = %s, want %s", tt.month, tt.day, got, tt.want)
+		}
+	}
+}
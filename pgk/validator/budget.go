@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"fmt"
+	"go-subscriptions-service/internal/dto"
+	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/pgk/currency"
+	"go-subscriptions-service/pgk/money"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+func ValidateBudgetRequest(req *dto.BudgetRequest) error {
+	log.Println("validateBudgetRequest (handler): called with req=", req)
+	if req.Amount == "" {
+		log.Println("validateBudgetRequest (handler) error: amount is required")
+		return fieldError("amount", "amount is required")
+	}
+
+	amount, err := money.Parse(req.Amount.String(), currency.Default)
+	if err != nil {
+		log.Println("validateBudgetRequest (handler) error: invalid amount format")
+		return fieldError("amount", "invalid amount format (expected decimal like 5000.00)")
+	}
+
+	if !amount.IsPositive() {
+		log.Println("validateBudgetRequest (handler) error: amount must be greater than 0")
+		return fieldError("amount", "amount must be greater than 0")
+	}
+
+	if req.Currency != "" && !currency.IsSupported(req.Currency) {
+		log.Println("validateBudgetRequest (handler) error: unsupported currency")
+		return fieldError("currency", fmt.Sprintf("unsupported currency (expected one of %s)", currency.SupportedList()))
+	}
+
+	log.Println("validateBudgetRequest (handler) success: request is valid")
+	return nil
+}
+
+func ValidateBudget(b *model.Budget) error {
+	log.Printf("validateBudget (service) called: user_id=%v, amount=%v, currency=%v, enforce=%v", b.UserID, b.Amount, b.Amount.Currency, b.Enforce)
+	if b.UserID == uuid.Nil {
+		log.Println("validateBudget (service) error: user ID must not be empty")
+		return fieldError("user_id", "user ID must not be empty")
+	}
+
+	if !b.Amount.IsPositive() {
+		log.Println("validateBudget (service) error: amount must be greater than 0")
+		return fieldError("amount", "amount must be greater than 0")
+	}
+
+	if !currency.IsSupported(b.Amount.Currency) {
+		log.Println("validateBudget (service) error: unsupported currency")
+		return fieldError("currency", fmt.Sprintf("unsupported currency %q", b.Amount.Currency))
+	}
+
+	log.Println("validateBudget (service) success: budget is valid")
+	return nil
+}
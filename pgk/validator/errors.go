@@ -0,0 +1,15 @@
+package validator
+
+// FieldError — ошибка проверки конкретного поля тела или параметра запроса.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+func fieldError(field, message string) error {
+	return &FieldError{Field: field, Message: message}
+}
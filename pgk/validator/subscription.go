@@ -1,9 +1,12 @@
 package validator
 
 import (
-	"errors"
+	"encoding/json"
+	"fmt"
 	"go-subscriptions-service/internal/dto"
 	"go-subscriptions-service/internal/model"
+	"go-subscriptions-service/pgk/currency"
+	"go-subscriptions-service/pgk/money"
 	"go-subscriptions-service/pgk/utils"
 	"log"
 
@@ -14,64 +17,387 @@ func ValidateCreateSubscriptionRequest(req *dto.SubscriptionRequest) error {
 	log.Println("validateCreateSubscriptionRequest (handler): called with req=", req)
 	if req.ServiceName == "" {
 		log.Println("validateCreateSubscriptionRequest (handler) error: service_name is required")
-		return errors.New("service_name is required")
+		return fieldError("service_name", "service_name is required")
 	}
 
-	if req.Price <= 0 {
-		log.Println("validateCreateSubscriptionRequest (handler) error: price must be greater than 0")
-		return errors.New("price must be greater than 0")
+	if err := validatePrice(req.Price.String(), req.Currency); err != nil {
+		log.Println("validateCreateSubscriptionRequest (handler) error:", err)
+		return err
+	}
+
+	if req.Currency != "" && !currency.IsSupported(req.Currency) {
+		log.Println("validateCreateSubscriptionRequest (handler) error: unsupported currency")
+		return fieldError("currency", fmt.Sprintf("unsupported currency (expected one of %s)", currency.SupportedList()))
+	}
+
+	if req.BillingCycle != "" && !isBillingCycle(req.BillingCycle) {
+		log.Println("validateCreateSubscriptionRequest (handler) error: invalid billing_cycle")
+		return fieldError("billing_cycle", "invalid billing_cycle (expected weekly, monthly, quarterly, yearly or custom)")
+	}
+
+	if req.BillingCycle == model.BillingCustom && req.BillingInterval <= 0 {
+		log.Println("validateCreateSubscriptionRequest (handler) error: billing_interval is required for custom billing_cycle")
+		return fieldError("billing_interval", "billing_interval must be greater than 0 for custom billing_cycle")
+	}
+
+	if req.BillingCycle != model.BillingCustom && req.BillingInterval != 0 {
+		log.Println("validateCreateSubscriptionRequest (handler) error: billing_interval is only allowed for custom billing_cycle")
+		return fieldError("billing_interval", "billing_interval is only allowed for custom billing_cycle")
 	}
 
 	if req.UserID == "" {
 		log.Println("validateCreateSubscriptionRequest (handler) error: user_id is required")
-		return errors.New("user_id is required")
+		return fieldError("user_id", "user_id is required")
 	}
 
 	if _, err := uuid.Parse(req.UserID); err != nil {
 		log.Println("validateCreateSubscriptionRequest (handler) error: invalid user_id format")
-		return errors.New("invalid user_id format")
+		return fieldError("user_id", "invalid user_id format")
+	}
+
+	if _, err := utils.ParseDate(req.StartDate); err != nil {
+		log.Println("validateCreateSubscriptionRequest (handler) error: invalid start_date format (expected YYYY-MM-DD or MM-YYYY)")
+		return fieldError("start_date", "invalid start_date format (expected YYYY-MM-DD or MM-YYYY)")
+	}
+
+	if req.EndDate != "" {
+		if _, err := utils.ParseDate(req.EndDate); err != nil {
+			log.Println("validateCreateSubscriptionRequest (handler) error: invalid end_date format (expected YYYY-MM-DD or MM-YYYY)")
+			return fieldError("end_date", "invalid end_date format (expected YYYY-MM-DD or MM-YYYY)")
+		}
+	}
+
+	if req.TrialEndDate != "" && req.TrialMonths != 0 {
+		log.Println("validateCreateSubscriptionRequest (handler) error: both trial_end_date and trial_months are set")
+		return fieldError("trial_months", "only one of trial_end_date and trial_months can be set")
 	}
 
-	if _, err := utils.ParseMonthYear(req.StartDate); err != nil {
-		log.Println("validateCreateSubscriptionRequest (handler) error: invalid start_date format (expected MM-YYYY)")
-		return errors.New("invalid start_date format (expected MM-YYYY)")
+	if req.TrialEndDate != "" {
+		if _, err := utils.ParseDate(req.TrialEndDate); err != nil {
+			log.Println("validateCreateSubscriptionRequest (handler) error: invalid trial_end_date format (expected YYYY-MM-DD or MM-YYYY)")
+			return fieldError("trial_end_date", "invalid trial_end_date format (expected YYYY-MM-DD or MM-YYYY)")
+		}
 	}
 
-	if _, err := utils.ParseMonthYear(req.EndDate); err != nil {
-		log.Println("validateCreateSubscriptionRequest (handler) error: invalid end_date format (expected MM-YYYY)")
-		return errors.New("invalid end_date format (expected MM-YYYY)")
+	if req.TrialMonths < 0 {
+		log.Println("validateCreateSubscriptionRequest (handler) error: trial_months must not be negative")
+		return fieldError("trial_months", "trial_months must not be negative")
 	}
 
 	log.Println("validateCreateSubscriptionRequest (handler) success: request is valid")
 	return nil
 }
 
+// ValidateSubscriptionPatchRequest проверяет поля, присутствующие в теле
+// PATCH; итоговая подписка после слияния проверяется ValidateSubcription.
+func ValidateSubscriptionPatchRequest(req *dto.SubscriptionPatchRequest) error {
+	log.Println("validateSubscriptionPatchRequest (handler): called")
+	if req.ServiceName != nil {
+		var name string
+		if err := patchField("service_name", req.ServiceName, &name); err != nil {
+			return err
+		}
+		if name == "" {
+			log.Println("validateSubscriptionPatchRequest (handler) error: service_name is empty")
+			return fieldError("service_name", "service_name must not be empty")
+		}
+	}
+
+	var cur string
+	if req.Currency != nil {
+		if err := patchField("currency", req.Currency, &cur); err != nil {
+			return err
+		}
+		if !currency.IsSupported(cur) {
+			log.Println("validateSubscriptionPatchRequest (handler) error: unsupported currency")
+			return fieldError("currency", fmt.Sprintf("unsupported currency (expected one of %s)", currency.SupportedList()))
+		}
+	}
+
+	if req.Price != nil {
+		var price json.Number
+		if err := patchField("price", req.Price, &price); err != nil {
+			return err
+		}
+		if err := validatePrice(price.String(), cur); err != nil {
+			log.Println("validateSubscriptionPatchRequest (handler) error:", err)
+			return err
+		}
+	}
+
+	if req.BillingCycle != nil {
+		var cycle string
+		if err := patchField("billing_cycle", req.BillingCycle, &cycle); err != nil {
+			return err
+		}
+		if !isBillingCycle(cycle) {
+			log.Println("validateSubscriptionPatchRequest (handler) error: invalid billing_cycle")
+			return fieldError("billing_cycle", "invalid billing_cycle (expected weekly, monthly, quarterly, yearly or custom)")
+		}
+	}
+
+	if req.BillingInterval != nil {
+		var interval int
+		if err := patchField("billing_interval", req.BillingInterval, &interval); err != nil {
+			return err
+		}
+		if interval < 0 {
+			log.Println("validateSubscriptionPatchRequest (handler) error: billing_interval is negative")
+			return fieldError("billing_interval", "billing_interval must not be negative")
+		}
+	}
+
+	if req.UserID != nil {
+		var userID string
+		if err := patchField("user_id", req.UserID, &userID); err != nil {
+			return err
+		}
+		if _, err := uuid.Parse(userID); err != nil {
+			log.Println("validateSubscriptionPatchRequest (handler) error: invalid user_id format")
+			return fieldError("user_id", "invalid user_id format")
+		}
+	}
+
+	for _, f := range []struct {
+		name     string
+		raw      json.RawMessage
+		nullable bool
+	}{
+		{"start_date", req.StartDate, false},
+		{"end_date", req.EndDate, true},
+		{"trial_end_date", req.TrialEndDate, true},
+	} {
+		if f.raw == nil || (f.nullable && isNull(f.raw)) {
+			continue
+		}
+		var date string
+		if err := patchField(f.name, f.raw, &date); err != nil {
+			return err
+		}
+		if f.nullable && date == "" {
+			continue
+		}
+		if _, err := utils.ParseDate(date); err != nil {
+			log.Printf("validateSubscriptionPatchRequest (handler) error: invalid %s format", f.name)
+			return fieldError(f.name, fmt.Sprintf("invalid %s format (expected YYYY-MM-DD or MM-YYYY)", f.name))
+		}
+	}
+
+	if req.TrialMonths != nil {
+		var months int
+		if err := patchField("trial_months", req.TrialMonths, &months); err != nil {
+			return err
+		}
+		if months < 0 {
+			log.Println("validateSubscriptionPatchRequest (handler) error: trial_months must not be negative")
+			return fieldError("trial_months", "trial_months must not be negative")
+		}
+		if req.TrialEndDate != nil {
+			log.Println("validateSubscriptionPatchRequest (handler) error: both trial_end_date and trial_months are set")
+			return fieldError("trial_months", "only one of trial_end_date and trial_months can be set")
+		}
+	}
+
+	log.Println("validateSubscriptionPatchRequest (handler) success: request is valid")
+	return nil
+}
+
+// patchField разбирает поле name тела PATCH в v; null для него недопустим.
+func patchField(name string, raw json.RawMessage, v interface{}) error {
+	if isNull(raw) {
+		log.Printf("validateSubscriptionPatchRequest (handler) error: %s is null", name)
+		return fieldError(name, fmt.Sprintf("%s must not be null", name))
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		log.Printf("validateSubscriptionPatchRequest (handler) error: invalid %s: %v", name, err)
+		return fieldError(name, fmt.Sprintf("invalid %s", name))
+	}
+	return nil
+}
+
+func isNull(raw json.RawMessage) bool {
+	return string(raw) == "null"
+}
+
 func ValidateSubcription(s *model.Subscription) error {
-	log.Printf("validating (service) called: service_name=%v, price=%v, user_id=%v, start_date=%v, end_date=%v", s.ServiceName, s.Price, s.UserID, s.StartDate, s.EndDate)
+	log.Printf("validating (service) called: service_name=%v, price=%v, currency=%v, user_id=%v, start_date=%v, end_date=%v", s.ServiceName, s.Price, s.Price.Currency, s.UserID, s.StartDate, s.EndDate)
 	if s.ServiceName == "" {
 		log.Println("validateSubcription (service) error: service name must not be empty")
-		return errors.New("service name must not be empty")
+		return fieldError("service_name", "service name must not be empty")
 	}
-	if s.Price <= 0 {
+	if !s.Price.IsPositive() {
 		log.Println("validateSubcription (service) error: price must be greater than 0")
-		return errors.New("price must be greater than 0")
+		return fieldError("price", "price must be greater than 0")
+	}
+
+	if !currency.IsSupported(s.Price.Currency) {
+		log.Println("validateSubcription (service) error: unsupported currency")
+		return fieldError("currency", fmt.Sprintf("unsupported currency %q", s.Price.Currency))
+	}
+
+	if s.BillingAnchorDay < 1 || s.BillingAnchorDay > 31 {
+		log.Println("validateSubcription (service) error: billing anchor day out of range")
+		return fieldError("start_date", "billing anchor day must be between 1 and 31")
+	}
+
+	if !isBillingCycle(s.BillingCycle) {
+		log.Println("validateSubcription (service) error: invalid billing cycle")
+		return fieldError("billing_cycle", fmt.Sprintf("invalid billing cycle %q", s.BillingCycle))
+	}
+
+	if s.BillingCycle == model.BillingCustom && s.BillingInterval <= 0 {
+		log.Println("validateSubcription (service) error: custom billing interval must be greater than 0")
+		return fieldError("billing_interval", "custom billing interval must be greater than 0")
+	}
+
+	if s.BillingCycle != model.BillingCustom && s.BillingInterval != 0 {
+		log.Println("validateSubcription (service) error: billing interval is only allowed for custom billing cycle")
+		return fieldError("billing_interval", "billing interval is only allowed for custom billing cycle")
 	}
 
 	if s.UserID == uuid.Nil {
 		log.Println("validateSubcription (service) error: user ID must not be empty")
-		return errors.New("user ID must not be empty")
+		return fieldError("user_id", "user ID must not be empty")
 	}
 
 	if s.StartDate.IsZero() {
 		log.Println("validateSubcription (service) error: start date must not be empty")
-		return errors.New("start date must not be empty")
+		return fieldError("start_date", "start date must not be empty")
 	}
 
 	if s.EndDate != nil && s.EndDate.Before(s.StartDate) {
 		log.Println("validateSubcription (service) error: end date must be after start date")
-		return errors.New("end date must be after start date")
+		return fieldError("end_date", "end date must be after start date")
+	}
+
+	if s.TrialEndDate != nil {
+		if s.TrialEndDate.Before(s.StartDate) {
+			log.Println("validateSubcription (service) error: trial end date is before start date")
+			return fieldError("trial_end_date", "trial end date must not be before start date")
+		}
+
+		if s.EndDate != nil && s.TrialEndDate.After(*s.EndDate) {
+			log.Println("validateSubcription (service) error: trial end date is after end date")
+			return fieldError("trial_end_date", "trial end date must not be after end date")
+		}
 	}
 
 	log.Println("validateSubcription (service) success: subscription is valid")
 	return nil
 }
+
+func isBillingCycle(cycle string) bool {
+	switch cycle {
+	case model.BillingWeekly, model.BillingMonthly, model.BillingQuarterly, model.BillingYearly, model.BillingCustom:
+		return true
+	}
+	return false
+}
+
+// validatePrice проверяет, что цена — положительная десятичная сумма с не
+// более чем двумя знаками после точки. Пустая валюта означает Default.
+func validatePrice(price, cur string) error {
+	if price == "" {
+		return fieldError("price", "price is required")
+	}
+
+	if cur == "" || !currency.IsSupported(cur) {
+		cur = currency.Default
+	}
+
+	m, err := money.Parse(price, cur)
+	if err != nil {
+		return fieldError("price", "invalid price format (expected decimal like 199.90)")
+	}
+
+	if !m.IsPositive() {
+		return fieldError("price", "price must be greater than 0")
+	}
+
+	return nil
+}
+
+func ValidatePriceChangeRequest(req *dto.PriceChangeRequest) error {
+	log.Println("validatePriceChangeRequest (handler): called with req=", req)
+	if err := validatePrice(req.Price.String(), ""); err != nil {
+		log.Println("validatePriceChangeRequest (handler) error:", err)
+		return err
+	}
+
+	if _, err := utils.ParseMonth(req.EffectiveFrom); err != nil {
+		log.Println("validatePriceChangeRequest (handler) error: invalid effective_from format (expected YYYY-MM-DD or MM-YYYY)")
+		return fieldError("effective_from", "invalid effective_from format (expected YYYY-MM-DD or MM-YYYY)")
+	}
+
+	log.Println("validatePriceChangeRequest (handler) success: request is valid")
+	return nil
+}
+
+func ValidatePriceChange(c *model.PriceChange, s *model.Subscription) error {
+	log.Printf("validatePriceChange (service) called: subscription_id=%v, price=%v, effective_from=%v", c.SubscriptionID, c.Price, c.EffectiveFrom)
+	if !c.Price.IsPositive() {
+		log.Println("validatePriceChange (service) error: price must be greater than 0")
+		return fieldError("price", "price must be greater than 0")
+	}
+
+	if c.Price.Currency != s.Price.Currency {
+		log.Println("validatePriceChange (service) error: currency differs from subscription currency")
+		return fieldError("price", fmt.Sprintf("price currency must match subscription currency %s", s.Price.Currency))
+	}
+
+	if c.EffectiveFrom.Before(utils.StartOfMonth(s.StartDate)) {
+		log.Println("validatePriceChange (service) error: effective date is before start date")
+		return fieldError("effective_from", "effective date must not be before subscription start date")
+	}
+
+	if s.EndDate != nil && c.EffectiveFrom.After(*s.EndDate) {
+		log.Println("validatePriceChange (service) error: effective date is after end date")
+		return fieldError("effective_from", "effective date must not be after subscription end date")
+	}
+
+	log.Println("validatePriceChange (service) success: price change is valid")
+	return nil
+}
+
+// maxCancelReasonLength — ограничение длины причины отмены в символах.
+const maxCancelReasonLength = 500
+
+func ValidateCancelRequest(req *dto.CancelRequest) error {
+	log.Println("validateCancelRequest (handler): called with req=", req)
+	if req.EffectiveMonth != "" {
+		if _, err := utils.ParseMonth(req.EffectiveMonth); err != nil {
+			log.Println("validateCancelRequest (handler) error: invalid effective_month format (expected YYYY-MM-DD or MM-YYYY)")
+			return fieldError("effective_month", "invalid effective_month format (expected YYYY-MM-DD or MM-YYYY)")
+		}
+	}
+
+	if len([]rune(req.Reason)) > maxCancelReasonLength {
+		log.Println("validateCancelRequest (handler) error: reason is too long")
+		return fieldError("reason", fmt.Sprintf("reason must be at most %d characters", maxCancelReasonLength))
+	}
+
+	log.Println("validateCancelRequest (handler) success: request is valid")
+	return nil
+}
+
+func ValidatePauseRequest(req *dto.PauseRequest) error {
+	log.Println("validatePauseRequest (handler): called with req=", req)
+	if req.EffectiveMonth != "" {
+		if _, err := utils.ParseMonth(req.EffectiveMonth); err != nil {
+			log.Println("validatePauseRequest (handler) error: invalid effective_month format (expected YYYY-MM-DD or MM-YYYY)")
+			return fieldError("effective_month", "invalid effective_month format (expected YYYY-MM-DD or MM-YYYY)")
+		}
+	}
+
+	log.Println("validatePauseRequest (handler) success: request is valid")
+	return nil
+}
+
+func ValidateStatus(status string) error {
+	switch status {
+	case model.StatusTrial, model.StatusActive, model.StatusPaused, model.StatusCancelled, model.StatusExpired:
+		return nil
+	}
+	log.Println("validateStatus (handler) error: invalid status ", status)
+	return fieldError("status", "invalid status (expected trial, active, paused, cancelled or expired)")
+}